@@ -1,6 +1,8 @@
 package console
 
 import (
+	"log"
+
 	"github.com/gothms/httpgo/app/console/command/demo"
 	"github.com/gothms/httpgo/framework"
 	"github.com/gothms/httpgo/framework/cobra"
@@ -25,6 +27,8 @@ func RunCommand(container framework.Container) error {
 		// 不需要出现cobra默认的completion子命令
 		CompletionOptions: cobra.CompletionOptions{DisableDefaultCmd: true},
 	}
+	// 项目基础目录，由 App 服务解析，这里声明是为了让所有命令都接受这个参数
+	rootCmd.PersistentFlags().String("base_folder", "", "base_folder参数, 默认为当前路径")
 	// 为根Command设置服务容器
 	rootCmd.SetContainer(container)
 	// 绑定框架的命令
@@ -39,4 +43,9 @@ func RunCommand(container framework.Container) error {
 func AddAppCommand(rootCmd *cobra.Command) {
 	//  demo 例子
 	rootCmd.AddCommand(demo.InitFoo())
+
+	// 定时任务，通过 cron start 启动调度器后执行
+	if err := rootCmd.AddCronCommand("0 * * * * *", demo.Foo1Command); err != nil {
+		log.Println("add cron command:", err)
+	}
 }
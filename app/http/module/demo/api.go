@@ -2,6 +2,7 @@ package demo
 
 import (
 	demoService "github.com/gothms/httpgo/app/provider/demo"
+	"github.com/gothms/httpgo/framework"
 	"github.com/gothms/httpgo/framework/gin"
 )
 
@@ -9,16 +10,44 @@ type DemoApi struct {
 	service *Service
 }
 
-func Register(r *gin.Engine) error {
-	api := NewDemoApi()
-	r.Bind(&demoService.DemoProvider{})
+// Module demo 模块，路由挂载在 /demo 下
+type Module struct{}
+
+var _ gin.Module = (*Module)(nil)
+
+func (m *Module) Name() string {
+	return "demo"
+}
+
+func (m *Module) Providers() []framework.ServiceProvider {
+	return []framework.ServiceProvider{&demoService.DemoProvider{}}
+}
 
-	r.GET("/demo/demo", api.Demo)
-	r.GET("/demo/demo2", api.Demo2)
-	r.POST("/demo/demo_post", api.DemoPost)
+func (m *Module) Middlewares() []gin.HandlerFunc {
 	return nil
 }
 
+func (m *Module) Routes(r *gin.RouterGroup) {
+	api := NewDemoApi()
+
+	r.GET("/demo", api.Demo).Doc(gin.RouteDoc{
+		Summary:   "获取所有用户",
+		Tags:      []string{"demo"},
+		Responses: map[int]interface{}{200: []UserDTO{}},
+	}).Name("demo.list")
+	r.GET("/demo2", api.Demo2).Doc(gin.RouteDoc{
+		Summary:   "获取所有学生",
+		Tags:      []string{"demo"},
+		Responses: map[int]interface{}{200: []UserDTO{}},
+	}).Name("demo.list2")
+	r.POST("/demo_post", api.DemoPost).Doc(gin.RouteDoc{
+		Summary:   "提交示例数据",
+		Tags:      []string{"demo"},
+		Request:   DemoPostParam{},
+		Responses: map[int]interface{}{200: nil},
+	}).Name("demo.post")
+}
+
 func NewDemoApi() *DemoApi {
 	service := NewService()
 	return &DemoApi{service: service}
@@ -54,15 +83,18 @@ func (api *DemoApi) Demo2(c *gin.Context) {
 	c.JSON(200, usersDTO)
 }
 
+// DemoPost godoc
+// @Summary 提交示例数据
+// @Accept  json
+// @Tags demo
+// @Param param body DemoPostParam true "示例数据"
+// @Success 200
+// @Router /demo/demo_post [post]
 func (api *DemoApi) DemoPost(c *gin.Context) {
 	//fmt.Println("/demo/demo_post")
-	type Foo struct {
-		Name string
-	}
-	foo := &Foo{}
-	err := c.BindJSON(&foo)
-	if err != nil {
-		c.AbortWithError(500, err)
+	foo := &DemoPostParam{}
+	if err := c.BindAndValidate(foo); err != nil {
+		return
 	}
 	c.JSON(200, nil)
 }
@@ -4,3 +4,8 @@ type UserDTO struct {
 	ID   int    `json:"id"`
 	Name string `json:"name"`
 }
+
+// DemoPostParam /demo/demo_post 的请求参数
+type DemoPostParam struct {
+	Name string `json:"name" binding:"required,max=32"`
+}
@@ -1,11 +1,72 @@
 package httpgo
 
 import (
+	"log"
+	"os"
+	"path/filepath"
+
 	"github.com/gothms/httpgo/app/http/module/demo"
 	"github.com/gothms/httpgo/framework/gin"
+	"github.com/gothms/httpgo/framework/middleware"
+	"github.com/gothms/httpgo/framework/openapi"
+	"github.com/gothms/httpgo/framework/util"
+)
+
+const (
+	// EnvKey 环境变量，值为 dev 时开启前端代理模式
+	EnvKey = "HTTPGO_ENV"
+	// FrontendDevKey 环境变量，前端 dev server 的地址
+	FrontendDevKey = "HTTPGO_FRONTEND_DEV"
+	// defaultFrontendDev 前端 dev server 的默认地址
+	defaultFrontendDev = "http://127.0.0.1:8071"
 )
 
 func Routes(r *gin.Engine) {
-	r.Static("/dist", "./dist/")
-	demo.Register(r)
+	// BindAndValidate 的错误信息根据 Accept-Language 翻译，字段名使用 json 标签
+	// 全局设置，只有第一次调用生效，重复调用 Routes 不会再修改校验器
+	if err := gin.UseErrorTranslator("en", "zh"); err != nil {
+		log.Println("use error translator:", err)
+	}
+	registerFrontend(r)
+	// 业务模块，路由挂载在 /模块名 下
+	for _, module := range []gin.Module{&demo.Module{}} {
+		if err := r.RegisterModule(module); err != nil {
+			log.Println("register module:", err)
+		}
+	}
+
+	// dev 模式下提供 Swagger UI：/swagger/
+	if os.Getenv(EnvKey) == "dev" {
+		openapi.RegisterUI(r, "/swagger", openapi.Options{Info: openapi.Info{Title: "httpgo", Version: "dev"}})
+	}
+}
+
+// registerFrontend 注册前端资源的路由
+// dev 模式下未匹配到 API 路由的请求都转发给前端 dev server，生产环境下在 /dist 下提供前端的静态文件
+func registerFrontend(r *gin.Engine) {
+	if os.Getenv(EnvKey) != "dev" {
+		r.Static("/dist", frontendFolder())
+		return
+	}
+
+	frontend := os.Getenv(FrontendDevKey)
+	if frontend == "" {
+		frontend = defaultFrontendDev
+	}
+	proxy, err := middleware.FrontendProxy(frontend)
+	if err != nil {
+		log.Println("frontend proxy disabled:", err)
+		r.Static("/dist", frontendFolder())
+		return
+	}
+	r.NoRoute(proxy)
+}
+
+// frontendFolder 前端静态文件的目录，优先使用 build frontend 的输出目录 dist/frontend，
+// 不存在时使用 dist，兼容直接把前端文件放在 dist 下的部署
+func frontendFolder() string {
+	if util.Exists(filepath.Join("dist", "frontend")) {
+		return "./dist/frontend/"
+	}
+	return "./dist/"
 }
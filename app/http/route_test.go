@@ -0,0 +1,82 @@
+package httpgo
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gothms/httpgo/framework/gin"
+)
+
+func TestRegisterFrontend(t *testing.T) {
+	frontend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "dev server "+r.URL.Path)
+	}))
+	defer frontend.Close()
+
+	// dist/frontend 相对于工作目录
+	wd, _ := os.Getwd()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "dist", "frontend"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "dist", "frontend", "index.html"), []byte("dist index"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	newRouter := func() *gin.Engine {
+		r := gin.New()
+		r.GET("/api/ping", func(c *gin.Context) { c.String(http.StatusOK, "pong") })
+		registerFrontend(r)
+		return r
+	}
+	get := func(r *gin.Engine, path string) (int, string) {
+		server := httptest.NewServer(r)
+		defer server.Close()
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	t.Setenv(EnvKey, "dev")
+	t.Setenv(FrontendDevKey, frontend.URL)
+	r := newRouter()
+	if code, body := get(r, "/src/main.js"); code != http.StatusOK || body != "dev server /src/main.js" {
+		t.Fatalf("non-API path should be proxied in dev mode, got %d %q", code, body)
+	}
+	if _, body := get(r, "/api/ping"); body != "pong" {
+		t.Fatalf("API route should not be proxied, got %q", body)
+	}
+
+	t.Setenv(EnvKey, "")
+	r = newRouter()
+	if code, body := get(r, "/dist/index.html"); code != http.StatusOK || body != "dist index" {
+		t.Fatalf("frontend should be served from dist, got %d %q", code, body)
+	}
+	if code, _ := get(r, "/src/main.js"); code != http.StatusNotFound {
+		t.Fatalf("non-API path should not be proxied outside dev mode, got %d", code)
+	}
+
+	// 没有 dist/frontend 时使用 dist 目录
+	if err := os.RemoveAll(filepath.Join(dir, "dist", "frontend")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "dist", "index.html"), []byte("legacy index"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r = newRouter()
+	if code, body := get(r, "/dist/index.html"); code != http.StatusOK || body != "legacy index" {
+		t.Fatalf("frontend should fall back to dist, got %d %q", code, body)
+	}
+}
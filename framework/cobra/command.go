@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/gothms/httpgo/framework"
+	"github.com/gothms/httpgo/framework/cron"
 	"io"
 	"os"
 	"path/filepath"
@@ -49,6 +50,11 @@ type Group struct {
 type Command struct {
 	container framework.Container
 
+	// Cron 定时任务调度器，只在根 Command 上设置，由 AddCronCommand 创建
+	Cron *cron.Cron
+	// CronSpecs 通过 AddCronCommand 注册的定时任务，用于 cron list 展示
+	CronSpecs []CronSpec
+
 	// Use is the one-line usage message.
 	// Recommended syntax is as follows:
 	//   [ ] identifies an optional argument. Arguments that are not enclosed in brackets are required.
@@ -1,6 +1,14 @@
 package cobra
 
-import "github.com/gothms/httpgo/framework"
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gothms/httpgo/framework"
+	"github.com/gothms/httpgo/framework/contract"
+	"github.com/gothms/httpgo/framework/cron"
+)
 
 // SetContainer 设置服务容器
 func (c *Command) SetContainer(container framework.Container) {
@@ -11,3 +19,110 @@ func (c *Command) SetContainer(container framework.Container) {
 func (c *Command) GetContainer() framework.Container {
 	return c.Root().container
 }
+
+// CronSpec 保存 Cron 命令的信息，用于展示
+type CronSpec struct {
+	// Type 任务类型，normal-cron 或者 distributed-cron
+	Type string
+	// Cmd 定时执行的命令
+	Cmd *Command
+	// Spec 6 个字段的 cron 表达式
+	Spec string
+	// EntryID 在调度器中的标识，用于查询下一次执行时间
+	EntryID cron.EntryID
+	// ServiceName 分布式任务的服务名，同名的任务在所有实例中每次只有一个执行
+	ServiceName string
+	// HoldTime 分布式任务的租约持有时间
+	HoldTime time.Duration
+}
+
+// AddCronCommand 将一个命令注册为定时任务，spec 为 6 个字段的 cron 表达式：秒 分 时 日 月 星期
+// 任务在 cron start 启动的调度器中执行，命令不需要通过 AddCommand 挂载到命令树上
+func (c *Command) AddCronCommand(spec string, cmd *Command) error {
+	return c.addCron(CronSpec{Type: "normal-cron", Cmd: cmd, Spec: spec}, nil)
+}
+
+// AddDistributedCronCommand 将一个命令注册为分布式定时任务
+// 每次执行前通过容器中的 contract.Distributed 抢占 serviceName 的租约，只有抢到租约的实例执行，
+// holdTime 为租约持有时间，持有者在租约过期前执行会续期，一般设置为略大于执行间隔
+func (c *Command) AddDistributedCronCommand(serviceName string, spec string, cmd *Command, holdTime time.Duration) error {
+	root := c.Root()
+	selected := func() bool {
+		container := root.GetContainer()
+		appService := container.MustMake(contract.AppKey).(contract.App)
+		distributedService := container.MustMake(contract.DistributedKey).(contract.Distributed)
+		appID := appService.AppID()
+		selectAppID, err := distributedService.Select(serviceName, appID, holdTime)
+		if err != nil {
+			log.Printf("cron command %s select error: %v", cmd.Name(), err)
+			return false
+		}
+		return selectAppID == appID
+	}
+	return c.addCron(CronSpec{
+		Type:        "distributed-cron",
+		Cmd:         cmd,
+		Spec:        spec,
+		ServiceName: serviceName,
+		HoldTime:    holdTime,
+	}, selected)
+}
+
+// addCron 将命令加入根命令的调度器，selected 不为空时，只有返回 true 才执行
+func (c *Command) addCron(spec CronSpec, selected func() bool) error {
+	root := c.Root()
+	if root.Cron == nil {
+		root.Cron = cron.New()
+	}
+
+	// 复制一份命令作为独立的根命令，共享容器，执行时不依赖命令树
+	cronCmd := *spec.Cmd
+	cronCmd.parent = nil
+	id, err := root.Cron.AddFunc(spec.Spec, func() {
+		if selected != nil && !selected() {
+			return
+		}
+		// 每次执行使用新的副本，同一个任务的多次执行之间互不影响
+		run := cronCmd
+		run.container = root.GetContainer()
+		run.ctx = root.Context()
+		if run.ctx == nil {
+			run.ctx = context.Background()
+		}
+		if err := run.runCron(); err != nil {
+			log.Printf("cron command %s error: %v", run.Name(), err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	spec.EntryID = id
+	root.CronSpecs = append(root.CronSpecs, spec)
+	return nil
+}
+
+// runCron 依次执行命令的 PreRun、Run、PostRun 钩子，不解析命令行参数
+func (c *Command) runCron() error {
+	args := []string{}
+	if c.PreRunE != nil {
+		if err := c.PreRunE(c, args); err != nil {
+			return err
+		}
+	} else if c.PreRun != nil {
+		c.PreRun(c, args)
+	}
+	if c.RunE != nil {
+		if err := c.RunE(c, args); err != nil {
+			return err
+		}
+	} else if c.Run != nil {
+		c.Run(c, args)
+	}
+	if c.PostRunE != nil {
+		return c.PostRunE(c, args)
+	} else if c.PostRun != nil {
+		c.PostRun(c, args)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package cobra
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gothms/httpgo/framework"
+	"github.com/gothms/httpgo/framework/contract"
+)
+
+func TestAddCronCommand(t *testing.T) {
+	container := framework.NewHttpgoContainer()
+	root := &Command{Use: "root"}
+	root.SetContainer(container)
+
+	var got framework.Container
+	job := &Command{
+		Use: "job",
+		RunE: func(c *Command, args []string) error {
+			got = c.GetContainer()
+			return nil
+		},
+	}
+	child := &Command{Use: "child"}
+	root.AddCommand(child)
+
+	if err := child.AddCronCommand("*/5 * * * * *", job); err != nil {
+		t.Fatal(err)
+	}
+	if root.Cron == nil || child.Cron != nil {
+		t.Fatal("cron should be created on the root command")
+	}
+	if len(root.CronSpecs) != 1 || root.CronSpecs[0].Spec != "*/5 * * * * *" || root.CronSpecs[0].Cmd != job {
+		t.Fatalf("unexpected cron specs %+v", root.CronSpecs)
+	}
+
+	entries := root.Cron.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("expected one cron entry, got %d", len(entries))
+	}
+	entries[0].Job.Run()
+	if got != container {
+		t.Fatal("cron command should share the root container")
+	}
+	if job.parent != nil {
+		t.Fatal("registering a cron command must not modify the command")
+	}
+}
+
+func TestAddCronCommandInvalidSpec(t *testing.T) {
+	root := &Command{Use: "root"}
+	if err := root.AddCronCommand("* * * * *", &Command{Use: "job"}); err == nil {
+		t.Fatal("expected error for 5-field spec")
+	}
+	if len(root.CronSpecs) != 0 {
+		t.Fatal("invalid spec must not be registered")
+	}
+}
+
+// fakeApp 测试用的 App 服务，只提供 AppID
+type fakeApp struct {
+	contract.App
+	appID string
+}
+
+func (a *fakeApp) AppID() string { return a.appID }
+
+// memoryDistributed 测试用的分布式选择器替身，多个容器共享同一个实例
+type memoryDistributed struct {
+	lock   sync.Mutex
+	owners map[string]string
+}
+
+func (d *memoryDistributed) Select(serviceName, appID string, holdTime time.Duration) (string, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if owner, ok := d.owners[serviceName]; ok {
+		return owner, nil
+	}
+	d.owners[serviceName] = appID
+	return appID, nil
+}
+
+// instanceProvider 将固定的实例绑定到容器
+type instanceProvider struct {
+	name     string
+	instance interface{}
+}
+
+func (p *instanceProvider) Register(framework.Container) framework.NewInstance {
+	return func(...interface{}) (interface{}, error) { return p.instance, nil }
+}
+func (p *instanceProvider) Boot(framework.Container) error           { return nil }
+func (p *instanceProvider) IsDefer() bool                            { return false }
+func (p *instanceProvider) Params(framework.Container) []interface{} { return nil }
+func (p *instanceProvider) Name() string                             { return p.name }
+
+func TestAddDistributedCronCommand(t *testing.T) {
+	backend := &memoryDistributed{owners: map[string]string{}}
+	var lock sync.Mutex
+	ran := map[string]int{}
+
+	var roots []*Command
+	for _, appID := range []string{"app1", "app2", "app3"} {
+		container := framework.NewHttpgoContainer()
+		container.Bind(&instanceProvider{name: contract.AppKey, instance: &fakeApp{appID: appID}})
+		container.Bind(&instanceProvider{name: contract.DistributedKey, instance: backend})
+		root := &Command{Use: "root"}
+		root.SetContainer(container)
+
+		job := &Command{
+			Use: "job",
+			Run: func(c *Command, args []string) {
+				lock.Lock()
+				ran[c.GetContainer().MustMake(contract.AppKey).(contract.App).AppID()]++
+				lock.Unlock()
+			},
+		}
+		if err := root.AddDistributedCronCommand("job", "* * * * * *", job, time.Second); err != nil {
+			t.Fatal(err)
+		}
+		if spec := root.CronSpecs[0]; spec.Type != "distributed-cron" || spec.ServiceName != "job" || spec.HoldTime != time.Second {
+			t.Fatalf("unexpected cron spec %+v", spec)
+		}
+		roots = append(roots, root)
+	}
+
+	// 模拟每个实例的多次调度
+	for i := 0; i < 3; i++ {
+		for _, root := range roots {
+			root.Cron.Entries()[0].Job.Run()
+		}
+	}
+	if len(ran) != 1 || ran["app1"] != 3 {
+		t.Fatalf("only the selected instance should run, got %v", ran)
+	}
+}
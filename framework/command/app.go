@@ -2,22 +2,47 @@ package command
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/gothms/httpgo/framework/cobra"
 	"github.com/gothms/httpgo/framework/contract"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 )
 
+// appAddress 服务监听的地址
+var appAddress string
+
+// appDaemon 是否以后台进程的方式启动
+var appDaemon bool
+
 // initAppCommand 初始化app命令和其子命令
 func initAppCommand() *cobra.Command {
+	appStartCommand.Flags().StringVar(&appAddress, "address", ":8080", "服务监听的地址")
+	appStartCommand.Flags().BoolVarP(&appDaemon, "daemon", "d", false, "是否以后台进程的方式启动")
+	appRestartCommand.Flags().StringVar(&appAddress, "address", ":8080", "服务监听的地址")
+
 	appCommand.AddCommand(appStartCommand)
+	appCommand.AddCommand(appStopCommand)
+	appCommand.AddCommand(appRestartCommand)
 	return appCommand
 }
 
+// appPidFile 记录服务进程 pid 的文件
+func appPidFile(appService contract.App) string {
+	return filepath.Join(appService.RuntimeFolder(), "app.pid")
+}
+
+// appLogFile 后台进程的输出文件
+func appLogFile(appService contract.App) string {
+	return filepath.Join(appService.LogFolder(), "app.log")
+}
+
 // AppCommand 是命令行参数第一级为app的命令，它没有实际功能，只是打印帮助文档
 var appCommand = &cobra.Command{
 	Use:   "app",
@@ -37,6 +62,18 @@ var appStartCommand = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// 从Command中获取服务容器
 		container := cmd.GetContainer()
+		appService := container.MustMake(contract.AppKey).(contract.App)
+		pidFile := appPidFile(appService)
+		if pid, err := readPidFile(pidFile); err != nil {
+			return err
+		} else if pid != 0 {
+			return fmt.Errorf("app already running, pid: %d", pid)
+		}
+
+		if appDaemon {
+			return startAppDaemon(appService)
+		}
+
 		// 从服务容器中获取kernel的服务实例
 		kernelService := container.MustMake(contract.KernelKey).(contract.Kernel)
 		// 从kernel服务实例中获取引擎
@@ -47,17 +84,24 @@ var appStartCommand = &cobra.Command{
 		// 创建一个Server服务
 		server := &http.Server{
 			Handler: core,
-			Addr:    ":8080",
+			Addr:    appAddress,
 		}
 
+		if err := writePidFile(pidFile); err != nil {
+			return err
+		}
+		defer os.Remove(pidFile)
+
 		// 这个goroutine是启动服务的goroutine
 		go func() {
 			//fmt.Println("ListenAndServe")
-			server.ListenAndServe()
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Println("ListenAndServe:", err)
+			}
 		}()
 
 		// 当前的goroutine等待信号量
-		quit := make(chan os.Signal)
+		quit := make(chan os.Signal, 1)
 		// 监控信号：SIGINT, SIGTERM, SIGQUIT
 		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
 		// 这里会阻塞当前goroutine等待信号
@@ -73,3 +117,56 @@ var appStartCommand = &cobra.Command{
 		return nil
 	},
 }
+
+// appStopCommand 停止后台运行的Web服务
+var appStopCommand = &cobra.Command{
+	Use:   "stop",
+	Short: "停止一个已经启动的Web服务",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		appService := cmd.GetContainer().MustMake(contract.AppKey).(contract.App)
+		pid, err := readPidFile(appPidFile(appService))
+		if err != nil {
+			return err
+		}
+		if pid == 0 {
+			fmt.Println("app not running")
+			return nil
+		}
+		if err := stopProcess(pid, 10*time.Second); err != nil {
+			return err
+		}
+		fmt.Println("app stopped, pid:", pid)
+		return nil
+	},
+}
+
+// appRestartCommand 重启Web服务，重启后的服务以后台进程的方式运行
+var appRestartCommand = &cobra.Command{
+	Use:   "restart",
+	Short: "重启一个Web服务",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		appService := cmd.GetContainer().MustMake(contract.AppKey).(contract.App)
+		pid, err := readPidFile(appPidFile(appService))
+		if err != nil {
+			return err
+		}
+		if pid != 0 {
+			if err := stopProcess(pid, 10*time.Second); err != nil {
+				return err
+			}
+			fmt.Println("app stopped, pid:", pid)
+		}
+		return startAppDaemon(appService)
+	},
+}
+
+// startAppDaemon 以后台进程的方式启动Web服务
+func startAppDaemon(appService contract.App) error {
+	args := []string{"app", "start", "--address", appAddress, "--base_folder", appService.BaseFolder()}
+	pid, err := startDaemon(args, appLogFile(appService))
+	if err != nil {
+		return err
+	}
+	fmt.Println("app started, pid:", pid, "log:", appLogFile(appService))
+	return nil
+}
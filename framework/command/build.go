@@ -0,0 +1,294 @@
+package command
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gothms/httpgo/framework"
+	"github.com/gothms/httpgo/framework/cobra"
+	"github.com/gothms/httpgo/framework/contract"
+	"github.com/gothms/httpgo/framework/util"
+)
+
+// 编译产物的目录结构，相对于输出目录
+//
+//	dist/
+//	  bin/<name>     后端二进制
+//	  frontend/      前端编译产物
+//	  SHA256SUMS     校验文件，可以用 sha256sum -c 校验
+const (
+	buildBinFolder      = "bin"
+	buildFrontendFolder = "frontend"
+	buildManifestFile   = "SHA256SUMS"
+)
+
+// versionPackage 版本信息注入的包
+const versionPackage = "github.com/gothms/httpgo/framework/provider/app"
+
+// buildConfig 对应配置目录下的 build.yaml
+type buildConfig struct {
+	// Output 编译产物的输出目录，相对于项目基础目录
+	Output  string `yaml:"output"`
+	Backend struct {
+		// Name 二进制文件名
+		Name string `yaml:"name"`
+		// Main main 包所在目录
+		Main string `yaml:"main"`
+		// Version 版本号，为空则使用 git describe 的结果
+		Version string `yaml:"version"`
+	} `yaml:"backend"`
+	Frontend struct {
+		// Folder package.json 所在目录
+		Folder string `yaml:"folder"`
+		// Script 执行的 npm script
+		Script string `yaml:"script"`
+		// Output 前端编译的输出目录，相对于 Folder
+		Output string `yaml:"output"`
+	} `yaml:"frontend"`
+}
+
+// newBuildConfig 读取 build 配置，并将未配置的字段设置为默认值
+func newBuildConfig(container framework.Container) (*buildConfig, error) {
+	cfg := &buildConfig{}
+	if err := loadConfig(container, "build", cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Output == "" {
+		cfg.Output = "dist"
+	}
+	if cfg.Backend.Name == "" {
+		cfg.Backend.Name = "httpgo"
+	}
+	if cfg.Backend.Main == "" {
+		cfg.Backend.Main = "."
+	}
+	if cfg.Frontend.Folder == "" {
+		cfg.Frontend.Folder = "frontend"
+	}
+	if cfg.Frontend.Script == "" {
+		cfg.Frontend.Script = "build"
+	}
+	if cfg.Frontend.Output == "" {
+		cfg.Frontend.Output = "dist"
+	}
+	return cfg, nil
+}
+
+// initBuildCommand 初始化build命令和其子命令
+func initBuildCommand() *cobra.Command {
+	buildCommand.AddCommand(buildBackendCommand)
+	buildCommand.AddCommand(buildFrontendCommand)
+	buildCommand.AddCommand(buildAllCommand)
+	return buildCommand
+}
+
+// buildCommand 编译相关的命令，它没有实际功能，只是打印帮助文档
+var buildCommand = &cobra.Command{
+	Use:   "build",
+	Short: "编译相关命令",
+	Long:  "编译相关命令，编译产物统一输出到 build.yaml 配置的 output 目录，并生成 SHA256SUMS 校验文件",
+	RunE: func(c *cobra.Command, args []string) error {
+		c.Help()
+		return nil
+	},
+}
+
+// buildBackendCommand 编译后端
+var buildBackendCommand = &cobra.Command{
+	Use:   "backend",
+	Short: "使用 go 编译后端，并注入版本信息",
+	RunE: func(c *cobra.Command, args []string) error {
+		base, cfg, err := buildPrepare(c)
+		if err != nil {
+			return err
+		}
+		if err := buildBackend(base, cfg); err != nil {
+			return err
+		}
+		return writeManifest(filepath.Join(base, cfg.Output))
+	},
+}
+
+// buildFrontendCommand 编译前端
+var buildFrontendCommand = &cobra.Command{
+	Use:   "frontend",
+	Short: "使用 npm 编译前端",
+	RunE: func(c *cobra.Command, args []string) error {
+		base, cfg, err := buildPrepare(c)
+		if err != nil {
+			return err
+		}
+		if err := buildFrontend(base, cfg); err != nil {
+			return err
+		}
+		return writeManifest(filepath.Join(base, cfg.Output))
+	},
+}
+
+// buildAllCommand 清空输出目录，同时编译前端和后端，没有前端项目的时候只编译后端
+var buildAllCommand = &cobra.Command{
+	Use:   "all",
+	Short: "同时编译前端和后端，没有前端项目时只编译后端",
+	RunE: func(c *cobra.Command, args []string) error {
+		base, cfg, err := buildPrepare(c)
+		if err != nil {
+			return err
+		}
+		output := filepath.Join(base, cfg.Output)
+		if err := os.RemoveAll(output); err != nil {
+			return err
+		}
+		if hasFrontend(base, cfg) {
+			if err := buildFrontend(base, cfg); err != nil {
+				return err
+			}
+		}
+		if err := buildBackend(base, cfg); err != nil {
+			return err
+		}
+		return writeManifest(output)
+	},
+}
+
+// buildPrepare 获取项目基础目录和 build 配置
+func buildPrepare(c *cobra.Command) (string, *buildConfig, error) {
+	container := c.GetContainer()
+	appService := container.MustMake(contract.AppKey).(contract.App)
+	cfg, err := newBuildConfig(container)
+	if err != nil {
+		return "", nil, err
+	}
+	return appService.BaseFolder(), cfg, nil
+}
+
+// buildBackend 编译后端二进制到 output/bin 目录下
+func buildBackend(base string, cfg *buildConfig) error {
+	version := cfg.Backend.Version
+	if version == "" {
+		version = gitOutput(base, "describe", "--tags", "--always", "--dirty")
+	}
+	commit := gitOutput(base, "rev-parse", "--short", "HEAD")
+	buildTime := time.Now().UTC().Format(time.RFC3339)
+
+	target := filepath.Join(base, cfg.Output, buildBinFolder, cfg.Backend.Name)
+	cmd := exec.Command("go", "build", "-trimpath", "-ldflags", backendLdflags(version, commit, buildTime), "-o", target, cfg.Backend.Main)
+	cmd.Dir = base
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("go build: %w", err)
+	}
+	fmt.Printf("build backend success: %s (version %s, commit %s, built %s)\n", target, version, commit, buildTime)
+	return nil
+}
+
+// backendLdflags 生成 go build 的 -ldflags，通过 -X 注入版本信息，version 和 commit 为空时不注入
+func backendLdflags(version, commit, buildTime string) string {
+	ldflags := []string{"-s", "-w"}
+	if version != "" {
+		ldflags = append(ldflags, "-X", versionPackage+".Version="+version)
+	}
+	if commit != "" {
+		ldflags = append(ldflags, "-X", versionPackage+".GitCommit="+commit)
+	}
+	ldflags = append(ldflags, "-X", versionPackage+".BuildTime="+buildTime)
+	return strings.Join(ldflags, " ")
+}
+
+// hasFrontend 项目中是否有前端，即配置的前端目录下有 package.json
+func hasFrontend(base string, cfg *buildConfig) bool {
+	return util.Exists(filepath.Join(base, cfg.Frontend.Folder, "package.json"))
+}
+
+// buildFrontend 执行配置的 npm script，并将产物复制到 output/frontend 目录下
+func buildFrontend(base string, cfg *buildConfig) error {
+	folder := filepath.Join(base, cfg.Frontend.Folder)
+	if !hasFrontend(base, cfg) {
+		return fmt.Errorf("package.json not found in %s", folder)
+	}
+	npm, err := exec.LookPath("npm")
+	if err != nil {
+		return fmt.Errorf("npm not found: %w", err)
+	}
+	cmd := exec.Command(npm, "run", cfg.Frontend.Script)
+	cmd.Dir = folder
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("npm run %s: %w", cfg.Frontend.Script, err)
+	}
+
+	target := filepath.Join(base, cfg.Output, buildFrontendFolder)
+	if err := os.RemoveAll(target); err != nil {
+		return err
+	}
+	if err := util.CopyFolder(filepath.Join(folder, cfg.Frontend.Output), target); err != nil {
+		return err
+	}
+	fmt.Println("build frontend success:", target)
+	return nil
+}
+
+// writeManifest 为输出目录下的所有文件生成 sha256 校验文件，按路径排序，格式兼容 sha256sum -c
+func writeManifest(output string) error {
+	var lines []string
+	err := filepath.Walk(output, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(output, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == buildManifestFile {
+			return nil
+		}
+		sum, err := fileSha256(path)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, sum+"  "+rel)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	manifest := filepath.Join(output, buildManifestFile)
+	return os.WriteFile(manifest, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+// fileSha256 计算文件的 sha256
+func fileSha256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// gitOutput 执行 git 命令并返回输出，不是 git 仓库或者执行失败时返回空字符串
+func gitOutput(dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
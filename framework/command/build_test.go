@@ -0,0 +1,118 @@
+package command
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gothms/httpgo/framework/provider/app"
+)
+
+func TestBackendLdflags(t *testing.T) {
+	got := backendLdflags("v1.2.0", "abc1234", "2024-01-02T03:04:05Z")
+	want := "-s -w -X " + versionPackage + ".Version=v1.2.0 -X " + versionPackage + ".GitCommit=abc1234 -X " + versionPackage + ".BuildTime=2024-01-02T03:04:05Z"
+	if got != want {
+		t.Fatalf("ldflags = %q, want %q", got, want)
+	}
+	if got := backendLdflags("", "", "now"); got != "-s -w -X "+versionPackage+".BuildTime=now" {
+		t.Fatalf("empty version and commit should not be injected, got %q", got)
+	}
+	// 引用这几个变量，重命名或者移动时编译失败
+	_, _, _ = app.Version, app.GitCommit, app.BuildTime
+}
+
+// TestBackendLdflagsInject 使用生成的 ldflags 实际编译，确认 -X 的目标变量存在并且被覆盖
+func TestBackendLdflagsInject(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skip go build in short mode")
+	}
+	bin := filepath.Join(t.TempDir(), "version")
+	build := exec.Command("go", "build", "-ldflags", backendLdflags("v9.9.9", "deadbee", "2024-01-02T03:04:05Z"), "-o", bin, "./testdata/version")
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("go build: %v\n%s", err, out)
+	}
+	out, err := exec.Command(bin).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "v9.9.9 deadbee 2024-01-02T03:04:05Z" {
+		t.Fatalf("injected version = %q", got)
+	}
+}
+
+func TestWriteManifest(t *testing.T) {
+	output := t.TempDir()
+	files := map[string]string{
+		"bin/httpgo":          "backend",
+		"frontend/index.html": "<html></html>",
+		"frontend/js/app.js":  "",
+	}
+	for name, content := range files {
+		path := filepath.Join(output, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 已有的 SHA256SUMS 不参与计算
+	if err := os.WriteFile(filepath.Join(output, buildManifestFile), []byte("stale\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeManifest(output); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(output, buildManifestFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	wantNames := []string{"bin/httpgo", "frontend/index.html", "frontend/js/app.js"}
+	if len(lines) != len(wantNames) {
+		t.Fatalf("manifest should have %d lines, got %q", len(wantNames), data)
+	}
+	for i, line := range lines {
+		sum, name, ok := strings.Cut(line, "  ")
+		if !ok || name != wantNames[i] {
+			t.Fatalf("line %d = %q, want \"<sha256>  %s\"", i, line, wantNames[i])
+		}
+		expect, err := fileSha256(filepath.Join(output, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sum != expect || len(sum) != 64 {
+			t.Fatalf("line %d sum = %q, want %q", i, sum, expect)
+		}
+	}
+	if !strings.HasPrefix(lines[2], "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  ") {
+		t.Fatalf("sha256 of empty file mismatch: %q", lines[2])
+	}
+	if sha256sum, err := exec.LookPath("sha256sum"); err == nil {
+		check := exec.Command(sha256sum, "-c", buildManifestFile)
+		check.Dir = output
+		if out, err := check.CombinedOutput(); err != nil {
+			t.Fatalf("sha256sum -c: %v\n%s", err, out)
+		}
+	}
+}
+
+func TestHasFrontend(t *testing.T) {
+	base := t.TempDir()
+	cfg := &buildConfig{}
+	cfg.Frontend.Folder = "frontend"
+	if hasFrontend(base, cfg) {
+		t.Fatal("project without package.json should have no frontend")
+	}
+	if err := os.MkdirAll(filepath.Join(base, "frontend"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(base, "frontend", "package.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !hasFrontend(base, cfg) {
+		t.Fatal("package.json should be detected")
+	}
+}
@@ -0,0 +1,25 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/gothms/httpgo/framework"
+	"github.com/gothms/httpgo/framework/contract"
+	"gopkg.in/yaml.v3"
+)
+
+// loadConfig 读取配置目录下的 name.yaml 到 cfg 中
+// 配置文件不存在的时候不报错，cfg 保留调用方设置的默认值
+func loadConfig(container framework.Container, name string, cfg interface{}) error {
+	appService := container.MustMake(contract.AppKey).(contract.App)
+	file := filepath.Join(appService.ConfigFolder(), name+".yaml")
+	content, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return yaml.Unmarshal(content, cfg)
+}
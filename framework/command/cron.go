@@ -0,0 +1,165 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/gothms/httpgo/framework/cobra"
+	"github.com/gothms/httpgo/framework/contract"
+	"github.com/gothms/httpgo/framework/cron"
+	"github.com/gothms/httpgo/framework/util"
+)
+
+// cronDaemon 是否以后台进程的方式启动调度器
+var cronDaemon bool
+
+// initCronCommand 初始化cron命令和其子命令
+func initCronCommand() *cobra.Command {
+	cronStartCommand.Flags().BoolVarP(&cronDaemon, "daemon", "d", false, "是否以后台进程的方式启动")
+
+	cronCommand.AddCommand(cronListCommand)
+	cronCommand.AddCommand(cronStartCommand)
+	cronCommand.AddCommand(cronStopCommand)
+	cronCommand.AddCommand(cronStateCommand)
+	return cronCommand
+}
+
+// cronPidFile 记录调度器进程 pid 的文件
+func cronPidFile(appService contract.App) string {
+	return filepath.Join(appService.RuntimeFolder(), "cron.pid")
+}
+
+// cronLogFile 后台调度器的输出文件
+func cronLogFile(appService contract.App) string {
+	return filepath.Join(appService.LogFolder(), "cron.log")
+}
+
+// cronCommand 定时任务相关的命令，它没有实际功能，只是打印帮助文档
+var cronCommand = &cobra.Command{
+	Use:   "cron",
+	Short: "定时任务相关命令",
+	RunE: func(c *cobra.Command, args []string) error {
+		c.Help()
+		return nil
+	},
+}
+
+// cronListCommand 列出所有定时任务
+var cronListCommand = &cobra.Command{
+	Use:   "list",
+	Short: "列出所有的定时任务",
+	RunE: func(c *cobra.Command, args []string) error {
+		root := c.Root()
+		if len(root.CronSpecs) == 0 {
+			fmt.Println("no cron command")
+			return nil
+		}
+		now := time.Now()
+		rows := [][]string{{"spec", "type", "command", "description", "next"}}
+		for _, spec := range root.CronSpecs {
+			next := "-"
+			if schedule, err := cron.Parse(spec.Spec); err == nil {
+				next = schedule.Next(now).Format("2006-01-02 15:04:05")
+			}
+			typ := spec.Type
+			if spec.ServiceName != "" {
+				typ += "(" + spec.ServiceName + ")"
+			}
+			rows = append(rows, []string{spec.Spec, typ, spec.Cmd.Use, spec.Cmd.Short, next})
+		}
+		util.PrettyPrint(rows)
+		return nil
+	},
+}
+
+// cronStartCommand 启动调度器
+var cronStartCommand = &cobra.Command{
+	Use:   "start",
+	Short: "启动定时任务调度器",
+	RunE: func(c *cobra.Command, args []string) error {
+		root := c.Root()
+		if root.Cron == nil || len(root.CronSpecs) == 0 {
+			fmt.Println("no cron command")
+			return nil
+		}
+		appService := c.GetContainer().MustMake(contract.AppKey).(contract.App)
+		pidFile := cronPidFile(appService)
+		if pid, err := readPidFile(pidFile); err != nil {
+			return err
+		} else if pid != 0 {
+			return fmt.Errorf("cron already running, pid: %d", pid)
+		}
+
+		if cronDaemon {
+			args := []string{"cron", "start", "--base_folder", appService.BaseFolder()}
+			pid, err := startDaemon(args, cronLogFile(appService))
+			if err != nil {
+				return err
+			}
+			fmt.Println("cron started, pid:", pid, "log:", cronLogFile(appService))
+			return nil
+		}
+
+		if err := writePidFile(pidFile); err != nil {
+			return err
+		}
+		defer os.Remove(pidFile)
+
+		fmt.Println("cron started, pid:", os.Getpid())
+		root.Cron.Start()
+
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
+		<-quit
+
+		// 等待正在执行的任务结束
+		<-root.Cron.Stop()
+		fmt.Println("cron stopped")
+		return nil
+	},
+}
+
+// cronStopCommand 停止后台运行的调度器
+var cronStopCommand = &cobra.Command{
+	Use:   "stop",
+	Short: "停止定时任务调度器",
+	RunE: func(c *cobra.Command, args []string) error {
+		appService := c.GetContainer().MustMake(contract.AppKey).(contract.App)
+		pid, err := readPidFile(cronPidFile(appService))
+		if err != nil {
+			return err
+		}
+		if pid == 0 {
+			fmt.Println("cron not running")
+			return nil
+		}
+		if err := stopProcess(pid, time.Minute); err != nil {
+			return err
+		}
+		fmt.Println("cron stopped, pid:", pid)
+		return nil
+	},
+}
+
+// cronStateCommand 查询调度器的运行状态
+var cronStateCommand = &cobra.Command{
+	Use:   "state",
+	Short: "查询定时任务调度器的运行状态",
+	RunE: func(c *cobra.Command, args []string) error {
+		appService := c.GetContainer().MustMake(contract.AppKey).(contract.App)
+		pid, err := readPidFile(cronPidFile(appService))
+		if err != nil {
+			return err
+		}
+		if pid == 0 {
+			fmt.Println("cron not running")
+			return nil
+		}
+		fmt.Println("cron running, pid:", pid)
+		return nil
+	},
+}
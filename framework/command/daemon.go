@@ -0,0 +1,89 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gothms/httpgo/framework/util"
+)
+
+// startDaemon 以后台进程的方式重新执行当前程序，标准输出和错误输出写入 logFile
+func startDaemon(args []string, logFile string) (int, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(logFile), os.ModePerm); err != nil {
+		return 0, err
+	}
+	out, err := os.OpenFile(logFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer out.Close()
+
+	cmd := exec.Command(executable, args...)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	cmd.SysProcAttr = daemonSysProcAttr()
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+	pid := cmd.Process.Pid
+	// 不等待子进程，释放相关资源
+	cmd.Process.Release()
+	return pid, nil
+}
+
+// writePidFile 将当前进程的 pid 写入文件
+func writePidFile(file string) error {
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(file, []byte(strconv.Itoa(os.Getpid())), 0644)
+}
+
+// readPidFile 读取 pid 文件，文件不存在或者进程已经不存在时返回 0
+func readPidFile(file string) (int, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return 0, fmt.Errorf("invalid pid file %s: %w", file, err)
+	}
+	if !util.CheckProcessExist(pid) {
+		return 0, nil
+	}
+	return pid, nil
+}
+
+// stopProcess 发送 SIGTERM 并等待进程退出
+func stopProcess(pid int, timeout time.Duration) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	if err := process.Signal(syscall.SIGTERM); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if !util.CheckProcessExist(pid) {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return errors.New("process " + strconv.Itoa(pid) + " did not exit in " + timeout.String())
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+// +build !windows
+
+package command
+
+import "syscall"
+
+// daemonSysProcAttr 后台进程使用新的 session，脱离当前终端
+func daemonSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
@@ -0,0 +1,11 @@
+//go:build windows
+// +build windows
+
+package command
+
+import "syscall"
+
+// daemonSysProcAttr windows 下没有 session 的概念，直接启动子进程
+func daemonSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{}
+}
@@ -0,0 +1,483 @@
+package command
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gothms/httpgo/framework/cobra"
+	"github.com/gothms/httpgo/framework/contract"
+	"github.com/gothms/httpgo/framework/util"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// deployConfig 对应配置目录下的 deploy.yaml
+type deployConfig struct {
+	// Connections 需要部署的机器
+	Connections []deployConnection `yaml:"connections"`
+	// RemoteFolder 远端的部署目录，每次部署在 releases 下创建一个新的版本目录，current 指向当前版本
+	// 所有版本的 storage 目录都指向 shared/storage，pid 文件和日志在版本之间共享
+	RemoteFolder string `yaml:"remote_folder"`
+	// KeepReleases 远端保留的版本数量，用于 deploy rollback
+	KeepReleases int `yaml:"keep_releases"`
+	// PreAction 切换版本前在新版本目录下执行的 shell 命令
+	PreAction []string `yaml:"pre_action"`
+	// PostAction 重启后在新版本目录下执行的 shell 命令
+	PostAction []string `yaml:"post_action"`
+}
+
+// deployConnection 一台机器的 ssh 连接配置
+type deployConnection struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	// RsaKey 私钥文件路径
+	RsaKey string `yaml:"rsa_key"`
+	// KnownHosts known_hosts 文件路径，为空时使用 ~/.ssh/known_hosts，文件不存在时连接失败
+	KnownHosts string `yaml:"known_hosts"`
+	// InsecureIgnoreHostKey 不校验远端的 host key，只用于测试环境，连接时会输出警告
+	InsecureIgnoreHostKey bool `yaml:"insecure_ignore_host_key"`
+	// Timeout 连接超时时间，单位秒
+	Timeout int `yaml:"timeout"`
+}
+
+// deployRemote 部署的远端，部署过程只依赖上传文件和执行命令两个能力
+type deployRemote interface {
+	// Upload 上传本地文件到远端路径，远端目录不存在时自动创建
+	Upload(local, remote string) error
+	// Run 在远端执行 shell 命令，返回标准输出
+	Run(cmd string) (string, error)
+	Close() error
+}
+
+// dialRemote 连接远端，测试时可以替换为本地的替身
+var dialRemote = dialSSH
+
+// newDeployConfig 读取 deploy 配置，并将未配置的字段设置为默认值
+func newDeployConfig(cmd *cobra.Command) (*deployConfig, error) {
+	cfg := &deployConfig{}
+	if err := loadConfig(cmd.GetContainer(), "deploy", cfg); err != nil {
+		return nil, err
+	}
+	if len(cfg.Connections) == 0 {
+		return nil, errors.New("no connections configured in deploy.yaml")
+	}
+	if cfg.RemoteFolder == "" {
+		return nil, errors.New("remote_folder not configured in deploy.yaml")
+	}
+	if cfg.KeepReleases <= 0 {
+		cfg.KeepReleases = 5
+	}
+	return cfg, nil
+}
+
+// initDeployCommand 初始化deploy命令和其子命令
+func initDeployCommand() *cobra.Command {
+	deployCommand.AddCommand(deployRollbackCommand)
+	return deployCommand
+}
+
+// deployCommand 编译并部署到 deploy.yaml 配置的所有机器
+var deployCommand = &cobra.Command{
+	Use:   "deploy",
+	Short: "编译并通过 ssh 部署到远端机器",
+	Long:  "编译后端和前端，将编译产物和配置上传到 deploy.yaml 配置的所有机器，切换到新版本并执行 app restart",
+	RunE: func(c *cobra.Command, args []string) error {
+		container := c.GetContainer()
+		appService := container.MustMake(contract.AppKey).(contract.App)
+		cfg, err := newDeployConfig(c)
+		if err != nil {
+			return err
+		}
+		buildCfg, err := newBuildConfig(container)
+		if err != nil {
+			return err
+		}
+
+		// 编译，没有前端项目的时候只编译后端
+		base := appService.BaseFolder()
+		output := filepath.Join(base, buildCfg.Output)
+		if err := os.RemoveAll(output); err != nil {
+			return err
+		}
+		if hasFrontend(base, buildCfg) {
+			if err := buildFrontend(base, buildCfg); err != nil {
+				return err
+			}
+		}
+		if err := buildBackend(base, buildCfg); err != nil {
+			return err
+		}
+		if err := writeManifest(output); err != nil {
+			return err
+		}
+
+		files, err := collectDeployFiles(output, appService.ConfigFolder())
+		if err != nil {
+			return err
+		}
+		d := &deployer{config: cfg, binary: buildCfg.Backend.Name, out: os.Stdout}
+		release := time.Now().Format("20060102150405")
+		return d.eachRemote(func(remote deployRemote) error {
+			return d.deploy(remote, files, release)
+		})
+	},
+}
+
+// deployRollbackCommand 回滚到上一个版本，或者指定的版本
+var deployRollbackCommand = &cobra.Command{
+	Use:   "rollback [release]",
+	Short: "回滚到上一个版本，或者指定的版本",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(c *cobra.Command, args []string) error {
+		cfg, err := newDeployConfig(c)
+		if err != nil {
+			return err
+		}
+		buildCfg, err := newBuildConfig(c.GetContainer())
+		if err != nil {
+			return err
+		}
+		target := ""
+		if len(args) > 0 {
+			target = args[0]
+		}
+		d := &deployer{config: cfg, binary: buildCfg.Backend.Name, out: os.Stdout}
+		return d.eachRemote(func(remote deployRemote) error {
+			return d.rollback(remote, target)
+		})
+	},
+}
+
+// deployFile 需要上传的文件，remote 为相对于版本目录的路径
+type deployFile struct {
+	local  string
+	remote string
+}
+
+// collectDeployFiles 收集编译产物和配置文件，远端版本目录的结构为
+//
+//	dist/    编译产物
+//	config/  配置文件
+func collectDeployFiles(output, configFolder string) ([]deployFile, error) {
+	var files []deployFile
+	collect := func(root, prefix string) error {
+		return filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			rel, err := filepath.Rel(root, p)
+			if err != nil {
+				return err
+			}
+			files = append(files, deployFile{local: p, remote: path.Join(prefix, filepath.ToSlash(rel))})
+			return nil
+		})
+	}
+	if err := collect(output, "dist"); err != nil {
+		return nil, err
+	}
+	if util.Exists(configFolder) {
+		if err := collect(configFolder, "config"); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// deployer 负责单台机器上的部署和回滚
+type deployer struct {
+	config *deployConfig
+	// binary 后端二进制文件名，位于版本目录的 dist/bin 下
+	binary string
+	out    io.Writer
+}
+
+// eachRemote 依次连接每台机器并执行 fn，任意一台失败即返回
+func (d *deployer) eachRemote(fn func(remote deployRemote) error) error {
+	for _, conn := range d.config.Connections {
+		fmt.Fprintln(d.out, "deploy to", conn.Host)
+		remote, err := dialRemote(conn)
+		if err != nil {
+			return fmt.Errorf("connect %s: %w", conn.Host, err)
+		}
+		err = fn(remote)
+		remote.Close()
+		if err != nil {
+			return fmt.Errorf("deploy %s: %w", conn.Host, err)
+		}
+	}
+	return nil
+}
+
+// releasesFolder 远端存放所有版本的目录
+func (d *deployer) releasesFolder() string {
+	return path.Join(d.config.RemoteFolder, "releases")
+}
+
+// sharedStorage 远端所有版本共享的 storage 目录
+func (d *deployer) sharedStorage() string {
+	return path.Join(d.config.RemoteFolder, "shared", "storage")
+}
+
+// currentLink 远端指向当前版本的软链
+func (d *deployer) currentLink() string {
+	return path.Join(d.config.RemoteFolder, "current")
+}
+
+// deploy 上传新版本，切换并重启，最后清理旧版本
+func (d *deployer) deploy(remote deployRemote, files []deployFile, release string) error {
+	releaseFolder := path.Join(d.releasesFolder(), release)
+	if _, err := remote.Run("mkdir -p " + shellQuote(releaseFolder)); err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := remote.Upload(file.local, path.Join(releaseFolder, file.remote)); err != nil {
+			return fmt.Errorf("upload %s: %w", file.local, err)
+		}
+	}
+	fmt.Fprintln(d.out, "uploaded", len(files), "files to", releaseFolder)
+	if err := d.linkShared(remote, releaseFolder); err != nil {
+		return err
+	}
+
+	if err := d.runActions(remote, releaseFolder, d.config.PreAction); err != nil {
+		return err
+	}
+	if err := d.switchRelease(remote, releaseFolder); err != nil {
+		return err
+	}
+	if err := d.runActions(remote, releaseFolder, d.config.PostAction); err != nil {
+		return err
+	}
+	return d.prune(remote)
+}
+
+// rollback 切换到指定版本，未指定时切换到当前版本的上一个版本
+func (d *deployer) rollback(remote deployRemote, target string) error {
+	releases, err := d.releases(remote)
+	if err != nil {
+		return err
+	}
+	if target == "" {
+		current, err := remote.Run("readlink " + shellQuote(d.currentLink()))
+		if err != nil {
+			return err
+		}
+		current = path.Base(strings.TrimSpace(current))
+		idx := sort.SearchStrings(releases, current)
+		if idx >= len(releases) || releases[idx] != current {
+			return fmt.Errorf("current release %s not found", current)
+		}
+		if idx == 0 {
+			return errors.New("no previous release to rollback")
+		}
+		target = releases[idx-1]
+	} else if idx := sort.SearchStrings(releases, target); idx >= len(releases) || releases[idx] != target {
+		return fmt.Errorf("release %s not found", target)
+	}
+	releaseFolder := path.Join(d.releasesFolder(), target)
+	if err := d.linkShared(remote, releaseFolder); err != nil {
+		return err
+	}
+	return d.switchRelease(remote, releaseFolder)
+}
+
+// linkShared 将版本目录的 storage 指向 shared/storage
+// app restart 通过 storage/runtime 下的 pid 文件找到正在运行的旧进程，切换版本后仍然需要能读到它
+func (d *deployer) linkShared(remote deployRemote, releaseFolder string) error {
+	storage := path.Join(releaseFolder, "storage")
+	_, err := remote.Run("mkdir -p " + shellQuote(d.sharedStorage()) + " && rm -rf " + shellQuote(storage) +
+		" && ln -sfn " + shellQuote(d.sharedStorage()) + " " + shellQuote(storage))
+	return err
+}
+
+// switchRelease 将 current 指向版本目录，并重启服务
+func (d *deployer) switchRelease(remote deployRemote, releaseFolder string) error {
+	if _, err := remote.Run("ln -sfn " + shellQuote(releaseFolder) + " " + shellQuote(d.currentLink())); err != nil {
+		return err
+	}
+	current := d.currentLink()
+	restart := "cd " + shellQuote(current) + " && " + shellQuote("./"+path.Join("dist", buildBinFolder, d.binary)) +
+		" app restart --base_folder " + shellQuote(current)
+	out, err := remote.Run(restart)
+	if err != nil {
+		return fmt.Errorf("restart: %w", err)
+	}
+	fmt.Fprint(d.out, out)
+	fmt.Fprintln(d.out, "switched to", path.Base(releaseFolder))
+	return nil
+}
+
+// runActions 在版本目录下依次执行 shell 命令
+func (d *deployer) runActions(remote deployRemote, folder string, actions []string) error {
+	for _, action := range actions {
+		out, err := remote.Run("cd " + shellQuote(folder) + " && " + action)
+		if err != nil {
+			return fmt.Errorf("action %q: %w", action, err)
+		}
+		fmt.Fprint(d.out, out)
+	}
+	return nil
+}
+
+// releases 远端所有的版本，按时间从旧到新排序
+func (d *deployer) releases(remote deployRemote) ([]string, error) {
+	out, err := remote.Run("ls -1 " + shellQuote(d.releasesFolder()))
+	if err != nil {
+		return nil, err
+	}
+	releases := strings.Fields(out)
+	sort.Strings(releases)
+	return releases, nil
+}
+
+// prune 只保留最新的 KeepReleases 个版本
+func (d *deployer) prune(remote deployRemote) error {
+	releases, err := d.releases(remote)
+	if err != nil {
+		return err
+	}
+	for len(releases) > d.config.KeepReleases {
+		if _, err := remote.Run("rm -rf " + shellQuote(path.Join(d.releasesFolder(), releases[0]))); err != nil {
+			return err
+		}
+		releases = releases[1:]
+	}
+	return nil
+}
+
+// shellQuote 使用单引号包裹 shell 参数
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// hostKeyCallback 根据 known_hosts 校验远端的 host key，没有配置 KnownHosts 时使用 ~/.ssh/known_hosts
+func hostKeyCallback(conn deployConnection) (ssh.HostKeyCallback, error) {
+	if conn.InsecureIgnoreHostKey {
+		log.Printf("warning: host key of %s is not verified, insecure_ignore_host_key should only be used for testing", conn.Host)
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+	file := conn.KnownHosts
+	if file == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		file = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	callback, err := knownhosts.New(file)
+	if err != nil {
+		return nil, fmt.Errorf("load known_hosts: %w", err)
+	}
+	return callback, nil
+}
+
+// sshRemote 基于 ssh 的远端实现
+type sshRemote struct {
+	client *ssh.Client
+}
+
+// dialSSH 根据配置建立 ssh 连接
+func dialSSH(conn deployConnection) (deployRemote, error) {
+	var auths []ssh.AuthMethod
+	if conn.RsaKey != "" {
+		key, err := os.ReadFile(conn.RsaKey)
+		if err != nil {
+			return nil, err
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		auths = append(auths, ssh.PublicKeys(signer))
+	}
+	if conn.Password != "" {
+		auths = append(auths, ssh.Password(conn.Password))
+	}
+
+	callback, err := hostKeyCallback(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	port, timeout := conn.Port, conn.Timeout
+	if port == 0 {
+		port = 22
+	}
+	if timeout == 0 {
+		timeout = 10
+	}
+	client, err := ssh.Dial("tcp", net.JoinHostPort(conn.Host, strconv.Itoa(port)), &ssh.ClientConfig{
+		User:            conn.User,
+		Auth:            auths,
+		HostKeyCallback: callback,
+		Timeout:         time.Duration(timeout) * time.Second,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &sshRemote{client: client}, nil
+}
+
+// Upload 通过 ssh 会话的标准输入传输文件内容
+func (r *sshRemote) Upload(local, remote string) error {
+	f, err := os.Open(local)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	session, err := r.client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	session.Stdin = f
+	cmd := fmt.Sprintf("mkdir -p %s && cat > %s && chmod %o %s",
+		shellQuote(path.Dir(remote)), shellQuote(remote), info.Mode().Perm(), shellQuote(remote))
+	if out, err := session.CombinedOutput(cmd); err != nil {
+		return fmt.Errorf("%w: %s", err, out)
+	}
+	return nil
+}
+
+// Run 在远端执行命令
+func (r *sshRemote) Run(cmd string) (string, error) {
+	session, err := r.client.NewSession()
+	if err != nil {
+		return "", err
+	}
+	defer session.Close()
+	var stdout, stderr bytes.Buffer
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+	if err := session.Run(cmd); err != nil {
+		return stdout.String(), fmt.Errorf("%w: %s", err, stderr.String())
+	}
+	return stdout.String(), nil
+}
+
+// Close 关闭 ssh 连接
+func (r *sshRemote) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,362 @@
+//go:build !windows
+// +build !windows
+
+package command
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// startSSHServer 启动一个本地的 ssh 服务替身，exec 请求直接在本机通过 sh -c 执行
+func startSSHServer(t *testing.T) deployConnection {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if c.User() == "deploy" && string(pass) == "secret" {
+				return nil, nil
+			}
+			return nil, io.EOF
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSSHConn(conn, config)
+		}
+	}()
+
+	// 把服务端的 host key 写入 known_hosts
+	knownHosts := filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(listener.Addr().String())}, signer.PublicKey())
+	if err := os.WriteFile(knownHosts, []byte(line+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return deployConnection{Host: "127.0.0.1", Port: addr.Port, User: "deploy", Password: "secret", KnownHosts: knownHosts}
+}
+
+func serveSSHConn(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go func() {
+			defer channel.Close()
+			for req := range requests {
+				if req.Type != "exec" {
+					req.Reply(false, nil)
+					continue
+				}
+				var payload struct{ Command string }
+				ssh.Unmarshal(req.Payload, &payload)
+				req.Reply(true, nil)
+
+				cmd := exec.Command("sh", "-c", payload.Command)
+				cmd.Stdin = channel
+				cmd.Stdout = channel
+				cmd.Stderr = channel.Stderr()
+				status := uint32(0)
+				if err := cmd.Run(); err != nil {
+					status = 1
+					if exitErr, ok := err.(*exec.ExitError); ok {
+						status = uint32(exitErr.ExitCode())
+					}
+				}
+				exitStatus := make([]byte, 4)
+				binary.BigEndian.PutUint32(exitStatus, status)
+				channel.SendRequest("exit-status", false, exitStatus)
+				return
+			}
+		}()
+	}
+}
+
+// prepareDeployFiles 生成一个假的二进制，执行 app restart 时记录参数
+func prepareDeployFiles(t *testing.T, restartLog string) []deployFile {
+	return prepareDeployBinary(t, "#!/bin/sh\necho \"$PWD $@\" >> "+shellQuote(restartLog)+"\n")
+}
+
+// prepareDeployBinary 使用 script 作为假的二进制，和配置文件一起生成需要上传的文件
+func prepareDeployBinary(t *testing.T, script string) []deployFile {
+	local := t.TempDir()
+	output := filepath.Join(local, "dist")
+	binary := filepath.Join(output, buildBinFolder, "httpgo")
+	if err := os.MkdirAll(filepath.Dir(binary), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(binary, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := filepath.Join(local, "config")
+	if err := os.MkdirAll(config, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(config, "app.yaml"), []byte("name: httpgo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	files, err := collectDeployFiles(output, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestDeployAndRollback(t *testing.T) {
+	conn := startSSHServer(t)
+	remoteFolder := t.TempDir()
+	restartLog := filepath.Join(t.TempDir(), "restart.log")
+	files := prepareDeployFiles(t, restartLog)
+
+	d := &deployer{
+		config: &deployConfig{
+			Connections:  []deployConnection{conn},
+			RemoteFolder: remoteFolder,
+			KeepReleases: 2,
+			PreAction:    []string{"touch pre"},
+			PostAction:   []string{"touch post"},
+		},
+		binary: "httpgo",
+		out:    io.Discard,
+	}
+
+	for i := 1; i <= 3; i++ {
+		release := "2023010100000" + strconv.Itoa(i)
+		err := d.eachRemote(func(remote deployRemote) error {
+			return d.deploy(remote, files, release)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	releases, err := os.ReadDir(filepath.Join(remoteFolder, "releases"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(releases) != 2 || releases[0].Name() != "20230101000002" || releases[1].Name() != "20230101000003" {
+		t.Fatalf("unexpected releases after prune: %v", releases)
+	}
+	current := filepath.Join(remoteFolder, "current")
+	assertCurrent(t, current, "20230101000003")
+	for _, name := range []string{"dist/bin/httpgo", "config/app.yaml", "pre", "post"} {
+		if _, err := os.Stat(filepath.Join(current, name)); err != nil {
+			t.Errorf("missing %s in release: %v", name, err)
+		}
+	}
+	if info, err := os.Stat(filepath.Join(current, "dist/bin/httpgo")); err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("binary mode not preserved: %v %v", info, err)
+	}
+
+	err = d.eachRemote(func(remote deployRemote) error {
+		return d.rollback(remote, "")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertCurrent(t, current, "20230101000002")
+
+	err = d.eachRemote(func(remote deployRemote) error {
+		return d.rollback(remote, "")
+	})
+	if err == nil || !strings.Contains(err.Error(), "no previous release") {
+		t.Fatalf("expected no previous release error, got %v", err)
+	}
+
+	log, err := os.ReadFile(restartLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(log)), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 restarts, got %q", lines)
+	}
+	if !strings.HasSuffix(lines[0], "app restart --base_folder "+current) {
+		t.Errorf("unexpected restart command %q", lines[0])
+	}
+}
+
+func assertCurrent(t *testing.T, current, release string) {
+	t.Helper()
+	target, err := os.Readlink(current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(target) != release {
+		t.Fatalf("current points to %s, expected %s", target, release)
+	}
+}
+
+func TestDialSSHHostKey(t *testing.T) {
+	conn := startSSHServer(t)
+	remote, err := dialSSH(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote.Close()
+
+	// host key 不匹配时拒绝连接
+	other := startSSHServer(t)
+	mismatch := conn
+	mismatch.KnownHosts = other.KnownHosts
+	if _, err := dialSSH(mismatch); err == nil {
+		t.Fatal("expected host key mismatch error")
+	}
+
+	// 没有 known_hosts 文件时连接失败，除非显式地关闭校验
+	missing := conn
+	missing.KnownHosts = filepath.Join(t.TempDir(), "known_hosts")
+	if _, err := dialSSH(missing); err == nil || !strings.Contains(err.Error(), "known_hosts") {
+		t.Fatalf("expected known_hosts error, got %v", err)
+	}
+	t.Setenv("HOME", t.TempDir())
+	missing.KnownHosts = ""
+	if _, err := dialSSH(missing); err == nil || !strings.Contains(err.Error(), "known_hosts") {
+		t.Fatalf("expected default known_hosts error, got %v", err)
+	}
+	missing.InsecureIgnoreHostKey = true
+	remote, err = dialSSH(missing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote.Close()
+}
+
+func TestDialSSHAuthFailed(t *testing.T) {
+	conn := startSSHServer(t)
+	conn.Password = "wrong"
+	if _, err := dialSSH(conn); err == nil {
+		t.Fatal("expected auth error")
+	}
+}
+
+// restartScript 模拟 app restart：停止 storage/runtime/app.pid 记录的进程，启动新的进程并写入 pid
+const restartScript = `#!/bin/sh
+pidfile=storage/runtime/app.pid
+if [ -f "$pidfile" ]; then
+	kill "$(cat "$pidfile")" || exit 1
+fi
+mkdir -p storage/runtime
+sleep 60 </dev/null >/dev/null 2>&1 &
+echo $! > "$pidfile"
+`
+
+func TestDeployRestartStopsOldProcess(t *testing.T) {
+	conn := startSSHServer(t)
+	remoteFolder := t.TempDir()
+	files := prepareDeployBinary(t, restartScript)
+	d := &deployer{
+		config: &deployConfig{
+			Connections:  []deployConnection{conn},
+			RemoteFolder: remoteFolder,
+			KeepReleases: 5,
+		},
+		binary: "httpgo",
+		out:    io.Discard,
+	}
+	pidFile := filepath.Join(remoteFolder, "shared", "storage", "runtime", "app.pid")
+	t.Cleanup(func() {
+		if pid, err := readTestPid(pidFile); err == nil {
+			syscall.Kill(pid, syscall.SIGKILL)
+		}
+	})
+
+	var pids []int
+	deploy := func(release string, fn func(remote deployRemote) error) {
+		if err := d.eachRemote(fn); err != nil {
+			t.Fatal(err)
+		}
+		pid, err := readTestPid(pidFile)
+		if err != nil {
+			t.Fatalf("release %s: %v", release, err)
+		}
+		if !processAlive(pid) {
+			t.Fatalf("release %s: new process %d not running", release, pid)
+		}
+		for _, old := range pids {
+			if processAlive(old) {
+				t.Fatalf("release %s: old process %d still running", release, old)
+			}
+		}
+		pids = append(pids, pid)
+	}
+	for _, release := range []string{"20230101000001", "20230101000002"} {
+		deploy(release, func(remote deployRemote) error {
+			return d.deploy(remote, files, release)
+		})
+	}
+	deploy("rollback", func(remote deployRemote) error {
+		return d.rollback(remote, "")
+	})
+
+	for _, release := range []string{"20230101000001", "20230101000002"} {
+		target, err := os.Readlink(filepath.Join(remoteFolder, "releases", release, "storage"))
+		if err != nil || target != filepath.Join(remoteFolder, "shared", "storage") {
+			t.Fatalf("storage of %s should link to shared storage, got %q %v", release, target, err)
+		}
+	}
+}
+
+func readTestPid(pidFile string) (int, error) {
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+// processAlive 进程是否存在并且没有退出，容器中的孤儿进程可能不会被回收，僵尸进程视为已经退出
+func processAlive(pid int) bool {
+	if syscall.Kill(pid, 0) != nil {
+		return false
+	}
+	stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return true
+	}
+	fields := strings.Fields(string(stat[bytes.LastIndexByte(stat, ')')+1:]))
+	return len(fields) == 0 || fields[0] != "Z"
+}
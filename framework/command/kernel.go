@@ -1,9 +1,50 @@
 package command
 
-import "github.com/gothms/httpgo/framework/cobra"
+import (
+	"github.com/gothms/httpgo/framework/cobra"
+	"github.com/gothms/httpgo/framework/contract"
+	"github.com/gothms/httpgo/framework/gin"
+)
 
 // AddKernelCommands will add all command/ * to root command
 func AddKernelCommands(root *cobra.Command) {
 	root.AddCommand(DemoCommand)
 	root.AddCommand(initAppCommand())
+	root.AddCommand(initBuildCommand())
+	root.AddCommand(initDeployCommand())
+	root.AddCommand(initCronCommand())
+	root.AddCommand(initSwaggerCommand())
+	root.AddCommand(initRouteCommand())
+	addModuleCommands(root)
+}
+
+// addModuleCommands 把 http 引擎中注册的模块的命令添加到根命令，没有绑定 kernel 服务时跳过
+func addModuleCommands(root *cobra.Command) {
+	container := root.GetContainer()
+	if container == nil || !container.IsBind(contract.KernelKey) {
+		return
+	}
+	engine, err := kernelEngine(container)
+	if err != nil {
+		return
+	}
+	root.AddCommand(moduleCommands(engine.Modules())...)
+}
+
+// CommandModule 提供控制台命令的模块，gin.Module 实现这个接口后，命令会被添加到控制台的根命令中
+type CommandModule interface {
+	gin.Module
+	// Commands 模块的命令
+	Commands() []*cobra.Command
+}
+
+// moduleCommands 收集实现了 CommandModule 的模块的命令，按模块注册的顺序
+func moduleCommands(modules []gin.Module) []*cobra.Command {
+	var commands []*cobra.Command
+	for _, module := range modules {
+		if m, ok := module.(CommandModule); ok {
+			commands = append(commands, m.Commands()...)
+		}
+	}
+	return commands
 }
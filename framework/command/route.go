@@ -0,0 +1,137 @@
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gothms/httpgo/framework"
+	"github.com/gothms/httpgo/framework/cobra"
+	"github.com/gothms/httpgo/framework/contract"
+	"github.com/gothms/httpgo/framework/gin"
+	"github.com/gothms/httpgo/framework/util"
+)
+
+// routeMethod 只列出该请求方法的路由
+var routeMethod string
+
+// routePrefix 只列出该路径前缀的路由
+var routePrefix string
+
+// routeJSON 是否以 JSON 格式输出
+var routeJSON bool
+
+// initRouteCommand 初始化route命令和其子命令
+func initRouteCommand() *cobra.Command {
+	routeListCommand.Flags().StringVarP(&routeMethod, "method", "m", "", "只列出该请求方法的路由，例如：GET")
+	routeListCommand.Flags().StringVarP(&routePrefix, "prefix", "p", "", "只列出该路径前缀的路由，例如：/demo")
+	routeListCommand.Flags().BoolVar(&routeJSON, "json", false, "以 JSON 格式输出")
+
+	routeCommand.AddCommand(routeListCommand)
+	return routeCommand
+}
+
+// kernelEngine 从 kernel 服务中获取 gin 引擎
+func kernelEngine(container framework.Container) (*gin.Engine, error) {
+	kernelService := container.MustMake(contract.KernelKey).(contract.Kernel)
+	engine, ok := kernelService.HttpEngine().(*gin.Engine)
+	if !ok {
+		return nil, errors.New("http engine is not *gin.Engine")
+	}
+	return engine, nil
+}
+
+// routeItem 路由列表中的一行
+type routeItem struct {
+	Host        string `json:"host,omitempty"`
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Handler     string `json:"handler"`
+	Middlewares int    `json:"middlewares"`
+	Name        string `json:"name,omitempty"`
+}
+
+// routeCommand 路由相关的命令，它没有实际功能，只是打印帮助文档
+var routeCommand = &cobra.Command{
+	Use:   "route",
+	Short: "路由相关命令",
+	RunE: func(c *cobra.Command, args []string) error {
+		c.Help()
+		return nil
+	},
+}
+
+// routeListCommand 列出所有注册的路由
+var routeListCommand = &cobra.Command{
+	Use:   "list",
+	Short: "列出所有注册的路由",
+	RunE: func(c *cobra.Command, args []string) error {
+		engine, err := kernelEngine(c.GetContainer())
+		if err != nil {
+			return err
+		}
+		items := filterRoutes(engine.Routes(), routeMethod, routePrefix)
+
+		if routeJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(items)
+		}
+
+		hasHost := false
+		for _, item := range items {
+			if item.Host != "" {
+				hasHost = true
+				break
+			}
+		}
+		header := []string{"method", "path", "handler", "middlewares", "name"}
+		if hasHost {
+			header = append([]string{"host"}, header...)
+		}
+		rows := [][]string{header}
+		for _, item := range items {
+			row := []string{item.Method, item.Path, item.Handler, strconv.Itoa(item.Middlewares), item.Name}
+			if hasHost {
+				row = append([]string{item.Host}, row...)
+			}
+			rows = append(rows, row)
+		}
+		util.PrettyPrint(rows)
+		return nil
+	},
+}
+
+// filterRoutes 按请求方法和路径前缀过滤路由，并按 host、路径、请求方法排序
+func filterRoutes(routes gin.RoutesInfo, method, prefix string) []routeItem {
+	items := make([]routeItem, 0, len(routes))
+	for _, route := range routes {
+		if method != "" && !strings.EqualFold(route.Method, method) {
+			continue
+		}
+		if !strings.HasPrefix(route.Path, prefix) {
+			continue
+		}
+		items = append(items, routeItem{
+			Host:        route.Host,
+			Method:      route.Method,
+			Path:        route.Path,
+			Handler:     route.Handler,
+			Middlewares: route.Middlewares,
+			Name:        route.Name,
+		})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Host != items[j].Host {
+			return items[i].Host < items[j].Host
+		}
+		if items[i].Path != items[j].Path {
+			return items[i].Path < items[j].Path
+		}
+		return items[i].Method < items[j].Method
+	})
+	return items
+}
@@ -0,0 +1,63 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/gothms/httpgo/framework"
+	"github.com/gothms/httpgo/framework/cobra"
+	"github.com/gothms/httpgo/framework/gin"
+)
+
+func TestFilterRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	handler := func(c *gin.Context) {}
+	engine.Use(handler)
+	engine.POST("/demo/b", handler)
+	engine.GET("/demo/b", handler).Name("demo.b")
+	engine.GET("/demo/a", handler, handler)
+	engine.GET("/other", handler)
+
+	items := filterRoutes(engine.Routes(), "get", "/demo")
+	if len(items) != 2 {
+		t.Fatalf("expect 2 routes, got %d", len(items))
+	}
+	if items[0].Path != "/demo/a" || items[0].Middlewares != 2 {
+		t.Errorf("unexpected first route: %+v", items[0])
+	}
+	if items[1].Path != "/demo/b" || items[1].Name != "demo.b" || items[1].Middlewares != 1 {
+		t.Errorf("unexpected second route: %+v", items[1])
+	}
+
+	if items := filterRoutes(engine.Routes(), "", ""); len(items) != 4 || items[1].Method != "GET" || items[2].Method != "POST" {
+		t.Errorf("unexpected routes: %+v", items)
+	}
+}
+
+// plainModule 没有命令的模块
+type plainModule struct{ name string }
+
+func (m *plainModule) Name() string                           { return m.name }
+func (m *plainModule) Providers() []framework.ServiceProvider { return nil }
+func (m *plainModule) Middlewares() []gin.HandlerFunc         { return nil }
+func (m *plainModule) Routes(group *gin.RouterGroup)          {}
+
+// commandModule 提供命令的模块
+type commandModule struct{ plainModule }
+
+func (m *commandModule) Commands() []*cobra.Command {
+	return []*cobra.Command{{Use: m.name}}
+}
+
+func TestModuleCommands(t *testing.T) {
+	var _ CommandModule = (*commandModule)(nil)
+	modules := []gin.Module{
+		&commandModule{plainModule{name: "blog"}},
+		&plainModule{name: "shop"},
+		&commandModule{plainModule{name: "wiki"}},
+	}
+	commands := moduleCommands(modules)
+	if len(commands) != 2 || commands[0].Use != "blog" || commands[1].Use != "wiki" {
+		t.Fatalf("unexpected commands: %+v", commands)
+	}
+}
@@ -0,0 +1,153 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gothms/httpgo/framework"
+	"github.com/gothms/httpgo/framework/cobra"
+	"github.com/gothms/httpgo/framework/contract"
+	"github.com/gothms/httpgo/framework/openapi"
+	"gopkg.in/yaml.v3"
+)
+
+// swaggerOutput 文档的输出文件，为 - 时输出到标准输出
+var swaggerOutput string
+
+// swaggerFormat 文档的格式，json 或者 yaml，为空时根据输出文件的扩展名判断
+var swaggerFormat string
+
+// swaggerHost 只生成该 host 模式下的路由
+var swaggerHost string
+
+// swaggerConfig 对应配置目录下的 swagger.yaml
+type swaggerConfig struct {
+	// Title 文档标题
+	Title string `yaml:"title"`
+	// Description 文档描述
+	Description string `yaml:"description"`
+	// Servers 服务地址列表
+	Servers []string `yaml:"servers"`
+	// Output 默认的输出文件，相对于项目基础目录
+	Output string `yaml:"output"`
+}
+
+// newSwaggerConfig 读取 swagger 配置，并将未配置的字段设置为默认值
+func newSwaggerConfig(container framework.Container) (*swaggerConfig, error) {
+	cfg := &swaggerConfig{}
+	if err := loadConfig(container, "swagger", cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Title == "" {
+		cfg.Title = "httpgo"
+	}
+	if cfg.Output == "" {
+		cfg.Output = filepath.Join("docs", "openapi.json")
+	}
+	return cfg, nil
+}
+
+// initSwaggerCommand 初始化swagger命令和其子命令
+func initSwaggerCommand() *cobra.Command {
+	swaggerGenCommand.Flags().StringVarP(&swaggerOutput, "output", "o", "", "输出文件，默认为 swagger.yaml 中配置的 output，- 表示输出到标准输出")
+	swaggerGenCommand.Flags().StringVar(&swaggerFormat, "format", "", "文档格式：json 或者 yaml，默认根据输出文件的扩展名判断")
+	swaggerGenCommand.Flags().StringVar(&swaggerHost, "host", "", "只生成该 host 模式下的路由，默认为不区分 host 的路由")
+
+	swaggerCommand.AddCommand(swaggerGenCommand)
+	return swaggerCommand
+}
+
+// swaggerCommand 接口文档相关的命令，它没有实际功能，只是打印帮助文档
+var swaggerCommand = &cobra.Command{
+	Use:   "swagger",
+	Short: "接口文档相关命令",
+	RunE: func(c *cobra.Command, args []string) error {
+		c.Help()
+		return nil
+	},
+}
+
+// swaggerGenCommand 根据路由生成 OpenAPI 3 文档
+var swaggerGenCommand = &cobra.Command{
+	Use:   "gen",
+	Short: "根据路由和路由的文档信息生成 OpenAPI 3 文档",
+	RunE: func(c *cobra.Command, args []string) error {
+		container := c.GetContainer()
+		appService := container.MustMake(contract.AppKey).(contract.App)
+		cfg, err := newSwaggerConfig(container)
+		if err != nil {
+			return err
+		}
+
+		engine, err := kernelEngine(container)
+		if err != nil {
+			return err
+		}
+		opts := openapi.Options{
+			Info: openapi.Info{
+				Title:       cfg.Title,
+				Description: cfg.Description,
+				Version:     appService.Version(),
+			},
+			Host: swaggerHost,
+		}
+		for _, server := range cfg.Servers {
+			opts.Servers = append(opts.Servers, openapi.Server{URL: server})
+		}
+		doc, err := openapi.Generate(engine.Routes(), opts)
+		if err != nil {
+			return err
+		}
+
+		output := swaggerOutput
+		if output == "" {
+			output = filepath.Join(appService.BaseFolder(), cfg.Output)
+		}
+		content, err := marshalDocument(doc, swaggerDocFormat(output))
+		if err != nil {
+			return err
+		}
+		if output == "-" {
+			_, err = os.Stdout.Write(content)
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(output), os.ModePerm); err != nil {
+			return err
+		}
+		if err := os.WriteFile(output, content, 0644); err != nil {
+			return err
+		}
+		fmt.Println("openapi document generated:", output)
+		return nil
+	},
+}
+
+// swaggerDocFormat 返回文档的格式，未指定时根据扩展名判断，默认为 json
+func swaggerDocFormat(output string) string {
+	if swaggerFormat != "" {
+		return swaggerFormat
+	}
+	switch strings.ToLower(filepath.Ext(output)) {
+	case ".yaml", ".yml":
+		return "yaml"
+	}
+	return "json"
+}
+
+// marshalDocument 按格式序列化文档
+func marshalDocument(doc *openapi.Document, format string) ([]byte, error) {
+	switch format {
+	case "json":
+		content, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(content, '\n'), nil
+	case "yaml":
+		return yaml.Marshal(doc)
+	}
+	return nil, fmt.Errorf("unknown document format: %s", format)
+}
@@ -0,0 +1,12 @@
+// 打印 provider/app 中注入的版本信息，用于测试 build 命令的 ldflags
+package main
+
+import (
+	"fmt"
+
+	"github.com/gothms/httpgo/framework/provider/app"
+)
+
+func main() {
+	fmt.Println(app.Version, app.GitCommit, app.BuildTime)
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 )
 
 // Container 是一个服务容器，提供绑定服务和获取服务的功能
@@ -26,66 +27,64 @@ type Container interface {
 
 var _ Container = (*HttpgoContainer)(nil)
 
+// containerSnapshot 服务容器某一时刻的只读快照，发布后不再修改
+// 每次绑定服务提供者或者实例化服务时，复制一份修改后整体替换
+type containerSnapshot struct {
+	providers map[string]ServiceProvider
+	instances map[string]interface{}
+}
+
 // HttpgoContainer 是服务容器的具体实现
+// 读取已经实例化的服务时只读取快照，不加锁也不分配内存，修改容器时由 lock 串行化
 type HttpgoContainer struct {
 	Container // 实现接口的一种写法
-	// providers 存储注册的服务提供者，key为字符串凭证
-	providers map[string]ServiceProvider
-	// instance 存储具体的实例，key为字符串凭证
-	instances map[string]interface{}
+	// snapshot 当前的快照，存储注册的服务提供者和具体的实例，key为字符串凭证
+	snapshot atomic.Pointer[containerSnapshot]
 	// lock 用于锁住对容器的变更操作
-	lock sync.RWMutex
+	lock sync.Mutex
 }
 
 func NewHttpgoContainer() *HttpgoContainer {
-	return &HttpgoContainer{
+	h := &HttpgoContainer{}
+	h.snapshot.Store(&containerSnapshot{
 		providers: map[string]ServiceProvider{},
 		instances: map[string]interface{}{},
-		lock:      sync.RWMutex{},
-	}
+	})
+	return h
 }
 
 // PrintProviders 输出服务容器中注册的关键字
 func (h *HttpgoContainer) PrintProviders() []string {
-	ret := make([]string, len(h.providers))
-	i := 0
-	for _, provider := range h.providers {
-		//name := provider.Name()
-		//line := fmt.Sprint(name)
-		//ret[i] = line
-		//i++
-		ret[i] = fmt.Sprintf("%T", provider)
-		i++
+	providers := h.snapshot.Load().providers
+	ret := make([]string, 0, len(providers))
+	for _, provider := range providers {
+		ret = append(ret, fmt.Sprintf("%T", provider))
 	}
 	return ret
 }
 
 // Bind 将服务容器和关键字做了绑定
+// 服务提供者的 Boot 和实例化方法在锁外执行，它们可以通过容器获取其他服务
 func (h *HttpgoContainer) Bind(provider ServiceProvider) error {
-	h.lock.Lock()
-	defer h.lock.Unlock()
 	key := provider.Name()
-	h.providers[key] = provider
-	for i, sp := range h.providers {
-		fmt.Println("key:", key)
-		fmt.Printf("%s, %T\n", i, sp)
-	}
+	h.lock.Lock()
+	h.replace(func(s *containerSnapshot) {
+		s.providers[key] = provider
+		delete(s.instances, key) // 替换服务提供者后，旧的实例失效
+	})
+	h.lock.Unlock()
 
 	// if provider is not defer
 	if provider.IsDefer() == false {
-		if err := provider.Boot(h); err != nil {
-			return err
-		}
-		// 实例化方法
-		//fmt.Println(*h)
-		//fmt.Printf("h::%T\n", h)
-		params := provider.Params(h)
-		method := provider.Register(h)
-		instance, err := method(params...)
+		instance, err := h.newInstance(provider, nil)
 		if err != nil {
-			return errors.New(err.Error())
+			return err
 		}
-		h.instances[key] = instance
+		h.lock.Lock()
+		h.replace(func(s *containerSnapshot) {
+			s.instances[key] = instance
+		})
+		h.lock.Unlock()
 	}
 	return nil
 }
@@ -93,14 +92,11 @@ func (h *HttpgoContainer) Bind(provider ServiceProvider) error {
 func (h *HttpgoContainer) IsBind(key string) bool {
 	return h.findServiceProvider(key) != nil
 }
+
 func (h *HttpgoContainer) findServiceProvider(key string) ServiceProvider {
-	h.lock.RLock()
-	defer h.lock.RUnlock()
-	if sp, ok := h.providers[key]; ok {
-		return sp
-	}
-	return nil
+	return h.snapshot.Load().providers[key]
 }
+
 func (h *HttpgoContainer) Make(key string) (interface{}, error) {
 	return h.make(key, nil, false)
 }
@@ -117,27 +113,32 @@ func (h *HttpgoContainer) MakeNew(key string, params []interface{}) (interface{}
 	return h.make(key, params, true)
 }
 
-/*
-false：
-{<nil> map[httpgo:demo:0x13eb658] map[] {{1 0} 0 0 {{} -1073741824} {{} 0}}}
-h::*framework.HttpgoContainer
-true：
-{<nil> map[httpgo:demo:0x10db658] map[] {{0 0} 0 0 {{} 1} {{} 0}}}
-h::*framework.HttpgoContainer
-*/
+// replace 复制当前的快照，用 fn 修改后发布新的快照，调用方需要持有 lock
+func (h *HttpgoContainer) replace(fn func(s *containerSnapshot)) {
+	old := h.snapshot.Load()
+	s := &containerSnapshot{
+		providers: make(map[string]ServiceProvider, len(old.providers)+1),
+		instances: make(map[string]interface{}, len(old.instances)+1),
+	}
+	for k, v := range old.providers {
+		s.providers[k] = v
+	}
+	for k, v := range old.instances {
+		s.instances[k] = v
+	}
+	fn(s)
+	h.snapshot.Store(s)
+}
+
 func (h *HttpgoContainer) newInstance(sp ServiceProvider, params []interface{}) (interface{}, error) {
 	if err := sp.Boot(h); err != nil {
 		return nil, err
 	}
 	if params == nil {
-		//fmt.Println(*h)
-		//fmt.Printf("h::%T\n", h)
 		params = sp.Params(h)
 	}
 	method := sp.Register(h)
-	//fmt.Println("MakeNew:", method, params)
 	instance, err := method(params...)
-	//fmt.Println("MakeNew:", instance, method, params)
 	if err != nil {
 		return nil, errors.New(err.Error())
 	}
@@ -146,27 +147,33 @@ func (h *HttpgoContainer) newInstance(sp ServiceProvider, params []interface{})
 
 // 实例化一个服务
 func (h *HttpgoContainer) make(key string, params []interface{}, forceNew bool) (interface{}, error) {
-	h.lock.RLock()
-	defer h.lock.RUnlock()
+	s := h.snapshot.Load()
 	// 查询是否已经注册了这个服务提供者，如果没有，则返回error
-	sp := h.findServiceProvider(key)
-	if sp == nil {
+	sp, ok := s.providers[key]
+	if !ok {
 		return nil, errors.New("contract " + key + " not register yet")
 	}
 	if forceNew {
 		return h.newInstance(sp, params)
 	}
 	// 不需要强制重新实例化，如果容器中已经实例化了，那么就直接使用容器中的实例
-	if ins, ok := h.instances[key]; ok {
+	if ins, ok := s.instances[key]; ok {
 		return ins, nil
 	}
-	// 容器中还未实例化，则进行一次实例化
+
+	// 容器中还未实例化，则进行一次实例化，在锁外执行以便服务提供者通过容器获取其他服务
 	inst, err := h.newInstance(sp, nil)
-	//fmt.Println("instance:", inst)
 	if err != nil {
 		return nil, err
 	}
-
-	h.instances[key] = inst
+	// 并发实例化同一个服务时，只保留最先发布的实例
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if ins, ok := h.snapshot.Load().instances[key]; ok {
+		return ins, nil
+	}
+	h.replace(func(s *containerSnapshot) {
+		s.instances[key] = inst
+	})
 	return inst, nil
 }
@@ -5,6 +5,8 @@ const AppKey = "httpgo:app"
 
 // App 定义接口
 type App interface {
+	// AppID 当前实例的唯一标识，每次启动都不同，用于分布式场景下区分实例
+	AppID() string
 	// Version 定义当前版本
 	Version() string
 	//BaseFolder 定义项目基础地址
@@ -19,6 +21,8 @@ type App interface {
 	MiddlewareFolder() string
 	// CommandFolder 定义业务定义的命令
 	CommandFolder() string
+	// StorageFolder 存放运行时产生的文件，例如日志和上传的文件
+	StorageFolder() string
 	// RuntimeFolder 定义业务的运行中间态信息
 	RuntimeFolder() string
 	// TestFolder 存放测试所需要的信息
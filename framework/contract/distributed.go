@@ -0,0 +1,15 @@
+package contract
+
+import "time"
+
+// DistributedKey 定义字符串凭证
+const DistributedKey = "httpgo:distributed"
+
+// Distributed 分布式选择器，多个实例对同一个服务进行抢占，只有一个实例能被选中
+// 默认提供基于本地文件的实现，适用于单机多进程；多机部署时可以绑定基于 Redis、数据库等的实现
+type Distributed interface {
+	// Select 抢占 serviceName 的租约
+	// appID 为当前实例的标识，holdTime 为租约的持有时间，租约未过期时持有者再次调用会续期
+	// 返回租约当前的持有者，等于 appID 表示当前实例被选中
+	Select(serviceName string, appID string, holdTime time.Duration) (selectAppID string, err error)
+}
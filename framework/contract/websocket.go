@@ -0,0 +1,29 @@
+package contract
+
+// WebSocketKey 定义字符串凭证
+const WebSocketKey = "httpgo:websocket"
+
+// WebSocketConn 一个 WebSocket 连接，gin.Context.UpgradeWebSocket 返回的 *gin.WebSocketConn 实现了这个接口
+type WebSocketConn interface {
+	// WriteMessage 写入一条消息，可以并发调用
+	WriteMessage(messageType int, data []byte) error
+	// Close 关闭连接
+	Close() error
+}
+
+// WebSocket 按房间管理 WebSocket 连接并广播消息，可以在多个 goroutine 中并发使用
+type WebSocket interface {
+	// Join 将连接加入房间
+	Join(conn WebSocketConn, rooms ...string)
+	// Leave 将连接移出房间
+	Leave(conn WebSocketConn, rooms ...string)
+	// Remove 将连接移出所有房间，连接结束时调用
+	Remove(conn WebSocketConn)
+	// Rooms 连接加入的房间，按名称排序
+	Rooms(conn WebSocketConn) []string
+	// Members 房间中的连接数
+	Members(room string) int
+	// Broadcast 向房间中除 except 以外的连接写入消息，返回写入成功的连接数
+	// 写入失败的连接会被移出所有房间并关闭
+	Broadcast(room string, messageType int, data []byte, except ...WebSocketConn) int
+}
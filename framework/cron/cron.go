@@ -0,0 +1,258 @@
+// Copyright (C) 2012 Rob Figueiredo. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+// 改写自 github.com/robfig/cron
+
+// Package cron 是一个支持秒级 cron 表达式的定时任务调度器
+package cron
+
+import (
+	"log"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Job 定时执行的任务
+type Job interface {
+	Run()
+}
+
+// FuncJob 将函数包装为 Job
+type FuncJob func()
+
+// Run 执行函数
+func (f FuncJob) Run() { f() }
+
+// EntryID 任务的唯一标识
+type EntryID int
+
+// Entry 调度器中的一个任务
+type Entry struct {
+	ID EntryID
+	// Spec 添加任务时使用的表达式
+	Spec     string
+	Schedule Schedule
+	// Next 下一次执行的时间，调度器未启动时为零值
+	Next time.Time
+	// Prev 上一次执行的时间，未执行过为零值
+	Prev time.Time
+	Job  Job
+}
+
+// Cron 定时任务调度器，可以在运行过程中添加和删除任务
+type Cron struct {
+	entries []*Entry
+	nextID  EntryID
+	running bool
+	// wake 在任务变更或者停止时通知调度的 goroutine 重新计算
+	wake    chan struct{}
+	stop    chan struct{}
+	jobs    sync.WaitGroup
+	lock    sync.Mutex
+	now     func() time.Time
+	onPanic func(entry Entry, p interface{})
+}
+
+// New 创建一个调度器
+func New() *Cron {
+	return &Cron{
+		wake: make(chan struct{}, 1),
+		now:  time.Now,
+		onPanic: func(entry Entry, p interface{}) {
+			log.Printf("cron: job %d (%s) panic: %v\n%s", entry.ID, entry.Spec, p, debug.Stack())
+		},
+	}
+}
+
+// AddFunc 按 spec 定时执行函数
+func (c *Cron) AddFunc(spec string, cmd func()) (EntryID, error) {
+	return c.AddJob(spec, FuncJob(cmd))
+}
+
+// AddJob 按 spec 定时执行 Job
+func (c *Cron) AddJob(spec string, job Job) (EntryID, error) {
+	schedule, err := Parse(spec)
+	if err != nil {
+		return 0, err
+	}
+	return c.Schedule(spec, schedule, job), nil
+}
+
+// Schedule 按 schedule 定时执行 Job，spec 只用于展示
+func (c *Cron) Schedule(spec string, schedule Schedule, job Job) EntryID {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.nextID++
+	entry := &Entry{
+		ID:       c.nextID,
+		Spec:     spec,
+		Schedule: schedule,
+		Job:      job,
+	}
+	if c.running {
+		entry.Next = schedule.Next(c.now())
+	}
+	c.entries = append(c.entries, entry)
+	c.notify()
+	return entry.ID
+}
+
+// Remove 删除任务
+func (c *Cron) Remove(id EntryID) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for i, entry := range c.entries {
+		if entry.ID == id {
+			c.entries = append(c.entries[:i], c.entries[i+1:]...)
+			break
+		}
+	}
+	c.notify()
+}
+
+// Entries 返回所有任务的快照，按下一次执行时间排序
+func (c *Cron) Entries() []Entry {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	entries := make([]Entry, len(c.entries))
+	for i, entry := range c.entries {
+		entries[i] = *entry
+	}
+	sortEntries(entries)
+	return entries
+}
+
+// Start 在新的 goroutine 中启动调度器
+func (c *Cron) Start() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.running {
+		return
+	}
+	c.running = true
+	c.stop = make(chan struct{})
+	go c.run(c.stop)
+}
+
+// Run 在当前 goroutine 中运行调度器，直到 Stop 被调用
+func (c *Cron) Run() {
+	c.lock.Lock()
+	if c.running {
+		c.lock.Unlock()
+		return
+	}
+	c.running = true
+	c.stop = make(chan struct{})
+	stop := c.stop
+	c.lock.Unlock()
+	c.run(stop)
+}
+
+// Stop 停止调度器，返回的 channel 在所有正在执行的任务结束后关闭
+func (c *Cron) Stop() <-chan struct{} {
+	c.lock.Lock()
+	if c.running {
+		c.running = false
+		close(c.stop)
+	}
+	c.lock.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		c.jobs.Wait()
+		close(done)
+	}()
+	return done
+}
+
+// run 调度循环：等待最近一个任务的执行时间，执行所有到期的任务
+func (c *Cron) run(stop chan struct{}) {
+	c.lock.Lock()
+	now := c.now()
+	for _, entry := range c.entries {
+		entry.Next = entry.Schedule.Next(now)
+	}
+	c.lock.Unlock()
+
+	for {
+		c.lock.Lock()
+		var next time.Time
+		for _, entry := range c.entries {
+			if entry.Next.IsZero() {
+				continue
+			}
+			if next.IsZero() || entry.Next.Before(next) {
+				next = entry.Next
+			}
+		}
+		c.lock.Unlock()
+
+		var timer *time.Timer
+		if next.IsZero() {
+			// 没有任务时长时间休眠，等待任务变更
+			timer = time.NewTimer(100000 * time.Hour)
+		} else {
+			timer = time.NewTimer(next.Sub(c.now()))
+		}
+
+		select {
+		case <-timer.C:
+			c.lock.Lock()
+			now = c.now()
+			for _, entry := range c.entries {
+				if entry.Next.IsZero() || entry.Next.After(now) {
+					continue
+				}
+				c.startJob(*entry)
+				entry.Prev = entry.Next
+				entry.Next = entry.Schedule.Next(now)
+			}
+			c.lock.Unlock()
+		case <-c.wake:
+			timer.Stop()
+		case <-stop:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// startJob 在新的 goroutine 中执行任务，任务 panic 不会影响调度器
+func (c *Cron) startJob(entry Entry) {
+	c.jobs.Add(1)
+	go func() {
+		defer c.jobs.Done()
+		defer func() {
+			if p := recover(); p != nil {
+				c.onPanic(entry, p)
+			}
+		}()
+		entry.Job.Run()
+	}()
+}
+
+// notify 通知调度循环任务有变更，调用方需要持有锁
+func (c *Cron) notify() {
+	if !c.running {
+		return
+	}
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// sortEntries 按下一次执行时间排序，未调度的任务排在最后
+func sortEntries(entries []Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Next.IsZero() {
+			return false
+		}
+		if entries[j].Next.IsZero() {
+			return true
+		}
+		return entries[i].Next.Before(entries[j].Next)
+	})
+}
@@ -0,0 +1,90 @@
+package cron
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCronRunsJobs(t *testing.T) {
+	c := New()
+	var count int32
+	if _, err := c.AddFunc("* * * * * *", func() { atomic.AddInt32(&count, 1) }); err != nil {
+		t.Fatal(err)
+	}
+	c.Start()
+	defer c.Stop()
+
+	time.Sleep(2100 * time.Millisecond)
+	if n := atomic.LoadInt32(&count); n < 1 || n > 3 {
+		t.Fatalf("expected job to run once or twice per second, ran %d times", n)
+	}
+}
+
+func TestCronAddAndRemoveWhileRunning(t *testing.T) {
+	c := New()
+	c.Start()
+	defer c.Stop()
+
+	ran := make(chan struct{}, 10)
+	id, err := c.AddFunc("@every 1s", func() { ran <- struct{}{} })
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := c.Entries()
+	if len(entries) != 1 || entries[0].Next.IsZero() {
+		t.Fatalf("expected one scheduled entry, got %+v", entries)
+	}
+
+	select {
+	case <-ran:
+	case <-time.After(2 * time.Second):
+		t.Fatal("job added while running never ran")
+	}
+
+	c.Remove(id)
+	if len(c.Entries()) != 0 {
+		t.Fatal("entry not removed")
+	}
+}
+
+func TestCronRecoversPanic(t *testing.T) {
+	c := New()
+	panicked := make(chan struct{}, 1)
+	c.onPanic = func(entry Entry, p interface{}) { panicked <- struct{}{} }
+	c.AddFunc("* * * * * *", func() { panic("boom") })
+	c.Start()
+	defer c.Stop()
+
+	select {
+	case <-panicked:
+	case <-time.After(2 * time.Second):
+		t.Fatal("panic not recovered")
+	}
+}
+
+func TestCronStopWaitsRunningJobs(t *testing.T) {
+	c := New()
+	started := make(chan struct{})
+	var finished int32
+	c.AddFunc("* * * * * *", func() {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		atomic.StoreInt32(&finished, 1)
+	})
+	c.Start()
+	<-started
+	<-c.Stop()
+	if atomic.LoadInt32(&finished) != 1 {
+		t.Fatal("stop returned before running job finished")
+	}
+}
+
+func TestEntriesNotStarted(t *testing.T) {
+	c := New()
+	c.AddFunc("0 0 0 * * *", func() {})
+	entries := c.Entries()
+	if len(entries) != 1 || !entries[0].Next.IsZero() || entries[0].Spec != "0 0 0 * * *" {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+}
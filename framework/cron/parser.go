@@ -0,0 +1,246 @@
+// Copyright (C) 2012 Rob Figueiredo. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+// 改写自 github.com/robfig/cron
+
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule 描述任务的执行时间
+type Schedule interface {
+	// Next 返回 t 之后下一次执行的时间
+	Next(t time.Time) time.Time
+}
+
+// Parse 解析 6 个字段的 cron 表达式：秒 分 时 日 月 星期
+// 每个字段支持 *、?、数字、范围 a-b、步长 */n 和 a-b/n，以及用逗号分隔的列表，月和星期支持英文缩写
+// 同时支持 @yearly、@monthly、@weekly、@daily、@hourly 和 @every <duration>，
+// 可以用 TZ=Asia/Shanghai 前缀指定时区
+func Parse(spec string) (Schedule, error) {
+	if len(spec) == 0 {
+		return nil, fmt.Errorf("empty spec string")
+	}
+
+	loc := time.Local
+	if strings.HasPrefix(spec, "TZ=") || strings.HasPrefix(spec, "CRON_TZ=") {
+		i := strings.Index(spec, " ")
+		if i < 0 {
+			return nil, fmt.Errorf("missing fields after time zone in spec %q", spec)
+		}
+		eq := strings.Index(spec, "=")
+		var err error
+		if loc, err = time.LoadLocation(spec[eq+1 : i]); err != nil {
+			return nil, fmt.Errorf("provided bad location %s: %v", spec[eq+1:i], err)
+		}
+		spec = strings.TrimSpace(spec[i:])
+	}
+
+	if strings.HasPrefix(spec, "@") {
+		return parseDescriptor(spec, loc)
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 6 {
+		return nil, fmt.Errorf("expected exactly 6 fields, found %d: %s", len(fields), spec)
+	}
+
+	var err error
+	field := func(field string, r bounds) uint64 {
+		if err != nil {
+			return 0
+		}
+		var bits uint64
+		bits, err = getField(field, r)
+		return bits
+	}
+
+	var (
+		second     = field(fields[0], seconds)
+		minute     = field(fields[1], minutes)
+		hour       = field(fields[2], hours)
+		dayofmonth = field(fields[3], dom)
+		month      = field(fields[4], months)
+		dayofweek  = field(fields[5], dow)
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SpecSchedule{
+		Second:   second,
+		Minute:   minute,
+		Hour:     hour,
+		Dom:      dayofmonth,
+		Month:    month,
+		Dow:      dayofweek,
+		Location: loc,
+	}, nil
+}
+
+// getField 解析一个字段，返回允许的值的 bit 集合
+func getField(field string, r bounds) (uint64, error) {
+	var bits uint64
+	for _, expr := range strings.Split(field, ",") {
+		bit, err := getRange(expr, r)
+		if err != nil {
+			return bits, err
+		}
+		bits |= bit
+	}
+	return bits, nil
+}
+
+// getRange 解析形如 number、number-number、*、? 以及带 /step 的表达式
+func getRange(expr string, r bounds) (uint64, error) {
+	var (
+		start, end, step uint
+		rangeAndStep     = strings.Split(expr, "/")
+		lowAndHigh       = strings.Split(rangeAndStep[0], "-")
+		singleDigit      = len(lowAndHigh) == 1
+		err              error
+		extra            uint64
+	)
+
+	if lowAndHigh[0] == "*" || lowAndHigh[0] == "?" {
+		start = r.min
+		end = r.max
+		extra = starBit
+	} else {
+		start, err = parseIntOrName(lowAndHigh[0], r.names)
+		if err != nil {
+			return 0, err
+		}
+		switch len(lowAndHigh) {
+		case 1:
+			end = start
+		case 2:
+			end, err = parseIntOrName(lowAndHigh[1], r.names)
+			if err != nil {
+				return 0, err
+			}
+		default:
+			return 0, fmt.Errorf("too many hyphens: %s", expr)
+		}
+	}
+
+	switch len(rangeAndStep) {
+	case 1:
+		step = 1
+	case 2:
+		step, err = mustParseInt(rangeAndStep[1])
+		if err != nil {
+			return 0, err
+		}
+		// 形如 N/step 表示从 N 到最大值
+		if singleDigit {
+			end = r.max
+		}
+		if step > 1 {
+			extra = 0
+		}
+	default:
+		return 0, fmt.Errorf("too many slashes: %s", expr)
+	}
+
+	if start < r.min {
+		return 0, fmt.Errorf("beginning of range (%d) below minimum (%d): %s", start, r.min, expr)
+	}
+	if end > r.max {
+		return 0, fmt.Errorf("end of range (%d) above maximum (%d): %s", end, r.max, expr)
+	}
+	if start > end {
+		return 0, fmt.Errorf("beginning of range (%d) beyond end of range (%d): %s", start, end, expr)
+	}
+	if step == 0 {
+		return 0, fmt.Errorf("step of range should be a positive number: %s", expr)
+	}
+
+	return getBits(start, end, step) | extra, nil
+}
+
+// parseIntOrName 解析数字或者月、星期的英文缩写
+func parseIntOrName(expr string, names map[string]uint) (uint, error) {
+	if names != nil {
+		if namedInt, ok := names[strings.ToLower(expr)]; ok {
+			return namedInt, nil
+		}
+	}
+	return mustParseInt(expr)
+}
+
+// mustParseInt 解析非负整数
+func mustParseInt(expr string) (uint, error) {
+	num, err := strconv.Atoi(expr)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse int from %s: %s", expr, err)
+	}
+	if num < 0 {
+		return 0, fmt.Errorf("negative number (%d) not allowed: %s", num, expr)
+	}
+	return uint(num), nil
+}
+
+// getBits 将 [min, max] 中间隔 step 的值设置到 bit 集合中
+func getBits(min, max, step uint) uint64 {
+	var bits uint64
+	if step == 1 {
+		return ^(^uint64(0) << (max + 1)) & (^uint64(0) << min)
+	}
+	for i := min; i <= max; i += step {
+		bits |= 1 << i
+	}
+	return bits
+}
+
+// all 返回字段所有值的 bit 集合
+func all(r bounds) uint64 {
+	return getBits(r.min, r.max, 1) | starBit
+}
+
+// parseDescriptor 解析 @ 开头的预定义表达式
+func parseDescriptor(descriptor string, loc *time.Location) (Schedule, error) {
+	switch descriptor {
+	case "@yearly", "@annually":
+		return &SpecSchedule{
+			Second: 1 << seconds.min, Minute: 1 << minutes.min, Hour: 1 << hours.min,
+			Dom: 1 << dom.min, Month: 1 << months.min, Dow: all(dow), Location: loc,
+		}, nil
+	case "@monthly":
+		return &SpecSchedule{
+			Second: 1 << seconds.min, Minute: 1 << minutes.min, Hour: 1 << hours.min,
+			Dom: 1 << dom.min, Month: all(months), Dow: all(dow), Location: loc,
+		}, nil
+	case "@weekly":
+		return &SpecSchedule{
+			Second: 1 << seconds.min, Minute: 1 << minutes.min, Hour: 1 << hours.min,
+			Dom: all(dom), Month: all(months), Dow: 1 << dow.min, Location: loc,
+		}, nil
+	case "@daily", "@midnight":
+		return &SpecSchedule{
+			Second: 1 << seconds.min, Minute: 1 << minutes.min, Hour: 1 << hours.min,
+			Dom: all(dom), Month: all(months), Dow: all(dow), Location: loc,
+		}, nil
+	case "@hourly":
+		return &SpecSchedule{
+			Second: 1 << seconds.min, Minute: 1 << minutes.min, Hour: all(hours),
+			Dom: all(dom), Month: all(months), Dow: all(dow), Location: loc,
+		}, nil
+	}
+
+	const every = "@every "
+	if strings.HasPrefix(descriptor, every) {
+		duration, err := time.ParseDuration(descriptor[len(every):])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse duration %s: %s", descriptor, err)
+		}
+		return Every(duration), nil
+	}
+
+	return nil, fmt.Errorf("unrecognized descriptor: %s", descriptor)
+}
@@ -0,0 +1,105 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseErrors(t *testing.T) {
+	specs := []string{
+		"",
+		"* * * * *",
+		"60 * * * * *",
+		"* * 24 * * *",
+		"* * * 0 * *",
+		"* * * * 13 *",
+		"* * * * * 7",
+		"5-1 * * * * *",
+		"*/0 * * * * *",
+		"1-2-3 * * * * *",
+		"a * * * * *",
+		"@every abc",
+		"@unknown",
+		"TZ=Nowhere/Nowhere * * * * * *",
+	}
+	for _, spec := range specs {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("expected error for spec %q", spec)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		time, spec, expected string
+	}{
+		// 简单的秒和分
+		{"Mon Jul 9 14:45:00 2012", "0 0/15 * * * *", "Mon Jul 9 15:00:00 2012"},
+		{"Mon Jul 9 14:59:59 2012", "0 0/15 * * * *", "Mon Jul 9 15:00:00 2012"},
+		{"Mon Jul 9 14:45:00 2012", "*/10 * * * * ?", "Mon Jul 9 14:45:10 2012"},
+		{"Mon Jul 9 14:45:00 2012", "5,15 * * * * *", "Mon Jul 9 14:45:05 2012"},
+
+		// 跨小时、天、月、年
+		{"Mon Jul 9 23:59:59 2012", "0 0 * * * *", "Tue Jul 10 00:00:00 2012"},
+		{"Tue Jul 31 23:59:59 2012", "0 0 0 * * *", "Wed Aug 1 00:00:00 2012"},
+		{"Mon Dec 31 23:59:45 2012", "0 * * * * *", "Tue Jan 1 00:00:00 2013"},
+
+		// 月和星期的名字
+		{"Mon Jul 9 14:45:00 2012", "0 0 0 1 feb *", "Fri Feb 1 00:00:00 2013"},
+		{"Mon Jul 9 14:45:00 2012", "0 0 9 * * mon-fri", "Tue Jul 10 09:00:00 2012"},
+
+		// 日和星期都指定时满足其一即可
+		{"Mon Jul 9 14:45:00 2012", "0 0 0 15 * sun", "Sun Jul 15 00:00:00 2012"},
+		{"Mon Jul 9 14:45:00 2012", "0 0 0 11 * sun", "Wed Jul 11 00:00:00 2012"},
+
+		// 闰年
+		{"Mon Jul 9 23:35:00 2012", "0 0 0 29 feb ?", "Sat Feb 29 00:00:00 2016"},
+
+		// 预定义表达式
+		{"Mon Jul 9 14:45:00 2012", "@hourly", "Mon Jul 9 15:00:00 2012"},
+		{"Mon Jul 9 14:45:00 2012", "@daily", "Tue Jul 10 00:00:00 2012"},
+		{"Mon Jul 9 14:45:00 2012", "@weekly", "Sun Jul 15 00:00:00 2012"},
+		{"Mon Jul 9 14:45:00 2012", "@monthly", "Wed Aug 1 00:00:00 2012"},
+		{"Mon Jul 9 14:45:00 2012", "@yearly", "Tue Jan 1 00:00:00 2013"},
+		{"Mon Jul 9 14:45:00 2012", "@every 90s", "Mon Jul 9 14:46:30 2012"},
+
+		// 不存在的日期
+		{"Mon Jul 9 14:45:00 2012", "0 0 0 30 feb ?", ""},
+	}
+
+	for _, c := range tests {
+		sched, err := Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		actual := sched.Next(getTime(c.time))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", c.time, c.spec, expected, actual)
+		}
+	}
+}
+
+func TestNextWithTimeZone(t *testing.T) {
+	sched, err := Parse("TZ=Asia/Shanghai 0 0 9 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2012, 7, 9, 0, 0, 0, 0, time.UTC)
+	expected := time.Date(2012, 7, 9, 1, 0, 0, 0, time.UTC)
+	if actual := sched.Next(from); !actual.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func getTime(value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	t, err := time.ParseInLocation("Mon Jan 2 15:04:05 2006", value, time.Local)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
@@ -0,0 +1,155 @@
+// Copyright (C) 2012 Rob Figueiredo. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+// 改写自 github.com/robfig/cron
+
+package cron
+
+import "time"
+
+// SpecSchedule 由 6 个字段的 cron 表达式解析得到，每个字段用一个 bit 集合表示允许的值
+type SpecSchedule struct {
+	Second, Minute, Hour, Dom, Month, Dow uint64
+
+	// Location 计算时间使用的时区
+	Location *time.Location
+}
+
+// bounds 字段的取值范围
+type bounds struct {
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	seconds = bounds{0, 59, nil}
+	minutes = bounds{0, 59, nil}
+	hours   = bounds{0, 23, nil}
+	dom     = bounds{1, 31, nil}
+	months  = bounds{1, 12, map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dow = bounds{0, 6, map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// starBit 标记字段是由 * 或 ? 指定的，用于日和星期的匹配规则
+const starBit = 1 << 63
+
+// Next 返回 t 之后下一次满足表达式的时间，5 年内都没有满足的时间则返回零值
+func (s *SpecSchedule) Next(t time.Time) time.Time {
+	origLocation := t.Location()
+	loc := t.Location()
+	if s.Location != nil && s.Location != time.Local {
+		loc = s.Location
+		t = t.In(loc)
+	}
+
+	// 从下一秒开始计算
+	t = t.Add(1*time.Second - time.Duration(t.Nanosecond())*time.Nanosecond)
+
+	// added 表示某个字段已经进位，低位字段需要从最小值开始
+	added := false
+	yearLimit := t.Year() + 5
+
+WRAP:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+
+	for 1<<uint(t.Month())&s.Month == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+		}
+		t = t.AddDate(0, 1, 0)
+		if t.Month() == time.January {
+			goto WRAP
+		}
+	}
+
+	for !dayMatches(s, t) {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		}
+		t = t.AddDate(0, 0, 1)
+		// 夏令时可能导致日期不在 0 点
+		if t.Hour() != 0 {
+			if t.Hour() > 12 {
+				t = t.Add(time.Duration(24-t.Hour()) * time.Hour)
+			} else {
+				t = t.Add(time.Duration(-t.Hour()) * time.Hour)
+			}
+		}
+		if t.Day() == 1 {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Hour())&s.Hour == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+		}
+		t = t.Add(1 * time.Hour)
+		if t.Hour() == 0 {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Minute())&s.Minute == 0 {
+		if !added {
+			added = true
+			t = t.Truncate(time.Minute)
+		}
+		t = t.Add(1 * time.Minute)
+		if t.Minute() == 0 {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Second())&s.Second == 0 {
+		if !added {
+			added = true
+			t = t.Truncate(time.Second)
+		}
+		t = t.Add(1 * time.Second)
+		if t.Second() == 0 {
+			goto WRAP
+		}
+	}
+
+	return t.In(origLocation)
+}
+
+// dayMatches 日和星期的匹配规则和 crontab 一致：
+// 两者都不是 * 时满足其一即可，否则两者都需要满足
+func dayMatches(s *SpecSchedule, t time.Time) bool {
+	domMatch := 1<<uint(t.Day())&s.Dom > 0
+	dowMatch := 1<<uint(t.Weekday())&s.Dow > 0
+	if s.Dom&starBit > 0 || s.Dow&starBit > 0 {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// ConstantDelaySchedule 固定间隔执行，对应 @every 表达式
+type ConstantDelaySchedule struct {
+	Delay time.Duration
+}
+
+// Every 返回固定间隔的 Schedule，间隔小于 1 秒时按 1 秒计算
+func Every(duration time.Duration) ConstantDelaySchedule {
+	if duration < time.Second {
+		duration = time.Second
+	}
+	return ConstantDelaySchedule{Delay: duration - time.Duration(duration.Nanoseconds())%time.Second}
+}
+
+// Next 返回 t 之后间隔 Delay 的时间，精确到秒
+func (s ConstantDelaySchedule) Next(t time.Time) time.Time {
+	return t.Add(s.Delay - time.Duration(t.Nanosecond())*time.Nanosecond)
+}
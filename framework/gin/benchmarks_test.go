@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"os"
 	"testing"
+
+	"github.com/gothms/httpgo/framework"
 )
 
 func BenchmarkOneRoute(B *testing.B) {
@@ -17,6 +19,37 @@ func BenchmarkOneRoute(B *testing.B) {
 	runRequest(B, router, "GET", "/ping")
 }
 
+func BenchmarkOneRouteMustMake(B *testing.B) {
+	router := New()
+	router.SetContainer(newBenchmarkContainer(B))
+	router.GET("/ping", func(c *Context) { c.MustMake("test:module") })
+	runRequest(B, router, "GET", "/ping")
+}
+
+func BenchmarkContextMustMake(B *testing.B) {
+	c := New()
+	c.SetContainer(newBenchmarkContainer(B))
+	ctx := c.allocateContext(0)
+	B.ReportAllocs()
+	B.ResetTimer()
+	for i := 0; i < B.N; i++ {
+		ctx.MustMake("test:module")
+	}
+}
+
+func BenchmarkContextMustMakeParallel(B *testing.B) {
+	c := New()
+	c.SetContainer(newBenchmarkContainer(B))
+	B.ReportAllocs()
+	B.ResetTimer()
+	B.RunParallel(func(pb *testing.PB) {
+		ctx := c.allocateContext(0)
+		for pb.Next() {
+			ctx.MustMake("test:module")
+		}
+	})
+}
+
 func BenchmarkRecoveryMiddleware(B *testing.B) {
 	router := New()
 	router.Use(Recovery())
@@ -145,6 +178,15 @@ func (m *mockWriter) WriteString(s string) (n int, err error) {
 
 func (m *mockWriter) WriteHeader(int) {}
 
+// newBenchmarkContainer 返回已经实例化了 test:module 服务的容器
+func newBenchmarkContainer(tb testing.TB) framework.Container {
+	container := framework.NewHttpgoContainer()
+	if err := container.Bind(&testModuleProvider{}); err != nil {
+		tb.Fatal(err)
+	}
+	return container
+}
+
 func runRequest(B *testing.B, r *Engine, method, path string) {
 	// create fake request
 	req, err := http.NewRequest(method, path, nil)
@@ -707,6 +707,11 @@ func (c *Context) BindUri(obj any) error {
 // See the binding package.
 func (c *Context) MustBindWith(obj any, b binding.Binding) error {
 	if err := c.ShouldBindWith(obj, b); err != nil {
+		if c.problemDetails() {
+			c.Error(err).SetType(ErrorTypeBind) //nolint: errcheck
+			c.AbortWithProblem(NewProblem(http.StatusBadRequest).WithDetail(err.Error()))
+			return err
+		}
 		c.AbortWithError(http.StatusBadRequest, err).SetType(ErrorTypeBind) //nolint: errcheck
 		return err
 	}
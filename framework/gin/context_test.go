@@ -2445,3 +2445,13 @@ func TestInterceptedHeader(t *testing.T) {
 	assert.Equal(t, "", w.Result().Header.Get("X-Test"))
 	assert.Equal(t, "present", w.Result().Header.Get("X-Test-2"))
 }
+
+func TestContextMustMakeNoAlloc(t *testing.T) {
+	c := New()
+	c.SetContainer(newBenchmarkContainer(t))
+	ctx := c.allocateContext(0)
+	ctx.MustMake("test:module")
+	if allocs := testing.AllocsPerRun(100, func() { ctx.MustMake("test:module") }); allocs != 0 {
+		t.Fatalf("MustMake on a warm container allocates %v times", allocs)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 const defaultMultipartMemory = 32 << 20 // 32 MB
@@ -65,6 +66,14 @@ type RouteInfo struct {
 	Path        string
 	Handler     string
 	HandlerFunc HandlerFunc
+	// Name 路由的名字，未命名为空
+	Name string
+	// Host 路由所属的 host 模式，默认路由树中的路由为空
+	Host string
+	// Doc 路由的文档信息，没有时为 nil
+	Doc *RouteDoc
+	// Middlewares 处理函数之前的中间件数量
+	Middlewares int
 }
 
 // RoutesInfo defines a RouteInfo slice.
@@ -113,6 +122,29 @@ type Engine struct {
 	// handler.
 	HandleMethodNotAllowed bool // 匹配失败，查看其他 Http Method 来处理 405
 
+	// HandleHEAD 开启后，HEAD 请求没有匹配的路由时使用同一路径的 GET 路由处理，保留响应头并丢弃响应体
+	HandleHEAD bool
+
+	// HandleOPTIONS 开启后，OPTIONS 请求没有匹配的路由时，根据路径匹配的所有路由树自动响应 204 和 Allow 响应头
+	// 全局中间件会先执行，可以在中间件中处理 CORS 预检请求
+	HandleOPTIONS bool
+
+	// ValidationStatus BindAndValidate 校验失败时的状态码，默认为 400，也可以设置为 422
+	ValidationStatus int
+
+	// ValidationRender BindAndValidate 失败时写入响应，为 nil 时写入 {"code","message","errors"} 格式的 JSON
+	ValidationRender func(c *Context, status int, message string, errs FieldErrors)
+
+	// ResponseEnvelope Success、Fail、ErrorResponder 和 Recovery 输出的响应格式，为 nil 时为 {"code","msg","data"}
+	ResponseEnvelope func(code int, message string, data interface{}) interface{}
+
+	// ProblemDetails 开启后，404、405、绑定失败、Recovery、Fail 和 BindAndValidate 的错误响应
+	// 使用 RFC 7807 定义的 application/problem+json 格式，见 Problem
+	ProblemDetails bool
+
+	// WebSocket Context.UpgradeWebSocket 使用的选项
+	WebSocket WebSocketOptions
+
 	// ForwardedByClientIP if enabled, client IP will be parsed from the request's headers that
 	// match those stored at `(*gin.Engine).RemoteIPHeaders`. If no IP was
 	// fetched, it falls back to the IP obtained from
@@ -163,14 +195,23 @@ type Engine struct {
 	FuncMap          template.FuncMap  // html/template包中的FuncMap map[string]interface{} ,用来定义从名称到函数的映射
 	allNoRoute       HandlersChain     // gin框架内部定义的一些属性：HandlersChain 是一个HandlerFunc 的数组(HandlerFunc其实就是一个Context的指针)
 	allNoMethod      HandlersChain
+	errorTranslator  *ErrorTranslator // BindAndValidate 使用的校验错误翻译
 	noRoute          HandlersChain
 	noMethod         HandlersChain
-	pool             sync.Pool   // 临时存取对象的集合(sync.Pool是线程安全的，主要用来缓存为使用的item以减少 GC 压力，使得创建高效且线程安全的空闲队列)
-	trees            methodTrees // 路由树的集合
-	maxParams        uint16      // 该路由可匹配到参数的最多数量：: 和 * 的总数
+	pool             sync.Pool     // 临时存取对象的集合(sync.Pool是线程安全的，主要用来缓存为使用的item以减少 GC 压力，使得创建高效且线程安全的空闲队列)
+	trees            methodTrees   // 路由树的集合
+	hosts            []*hostRouter // Host 注册的 host 路由，不含参数的 host 在前
+	maxParams        uint16        // 该路由可匹配到参数的最多数量：: 和 * 的总数
 	maxSections      uint16
 	trustedProxies   []string
 	trustedCIDRs     []*net.IPNet
+	lastRoutes       []routeKey                 // 最近一次注册的路由，用于 Name 命名和 Doc
+	namedRoutes      map[string]namedRoute      // 路由名字 => 路由
+	routeNames       map[routeKey]string        // 路由 => 路由名字
+	routeDocs        map[routeKey]*RouteDoc     // 路由 => 文档信息
+	modules          []Module                   // RegisterModule 注册的模块
+	routesMu         sync.Mutex                 // 运行时修改路由以及读写 lastRoutes、namedRoutes、routeNames、routeDocs 的锁，匹配请求时不需要加锁
+	table            atomic.Pointer[routeTable] // 运行时修改路由后发布的路由表，为 nil 时使用 trees 和 hosts
 
 	// 容器
 	container framework.Container
@@ -183,6 +224,10 @@ var _ IRouter = (*Engine)(nil)
 // - RedirectTrailingSlash:  true
 // - RedirectFixedPath:      false
 // - HandleMethodNotAllowed: false
+// - HandleHEAD:             false
+// - HandleOPTIONS:          false
+// - ValidationStatus:       400
+// - ProblemDetails:         false
 // - ForwardedByClientIP:    true
 // - UseRawPath:             false
 // - UnescapePathValues:     true
@@ -198,6 +243,10 @@ func New() *Engine {
 		RedirectTrailingSlash:  true,
 		RedirectFixedPath:      false,
 		HandleMethodNotAllowed: false,
+		HandleHEAD:             false,
+		HandleOPTIONS:          false,
+		ValidationStatus:       http.StatusBadRequest,
+		ProblemDetails:         false,
 		ForwardedByClientIP:    true,
 		RemoteIPHeaders:        []string{"X-Forwarded-For", "X-Real-IP"},
 		TrustedPlatform:        defaultPlatform,
@@ -215,6 +264,7 @@ func New() *Engine {
 		//container: framework.NewHttpgoContainer(),
 	}
 	engine.RouterGroup.engine = engine // 注册 RouterGroup.engine
+	engine.FuncMap["url"] = engine.URL // 模板中可以使用 {{ url "name" params... }} 生成路由的 URL
 	engine.pool.New = func() any {
 		return engine.allocateContext(engine.maxParams) // maxParams 的值的设置：func (engine *Engine) addRoute(method, path string, handlers HandlersChain) {
 	} // 实现 pool 的参数 New func() any
@@ -298,7 +348,14 @@ func (engine *Engine) SetHTMLTemplate(templ *template.Template) {
 
 // SetFuncMap sets the FuncMap used for template.FuncMap.
 // 设置一个FuncMap给template.FuncMap使用(内部其实设置了engine的FuncMap)
+// 未定义 url 函数时会自动加上 engine.URL
 func (engine *Engine) SetFuncMap(funcMap template.FuncMap) {
+	if funcMap == nil {
+		funcMap = template.FuncMap{}
+	}
+	if _, ok := funcMap["url"]; !ok {
+		funcMap["url"] = engine.URL
+	}
 	engine.FuncMap = funcMap
 }
 
@@ -338,22 +395,45 @@ func (engine *Engine) rebuild405Handlers() {
 }
 
 func (engine *Engine) addRoute(method, path string, handlers HandlersChain) {
+	engine.addHostRoute(nil, method, path, handlers)
+}
+
+// addHostRoute 向 host 的路由树中添加路由，host 为 nil 时添加到默认的路由树
+func (engine *Engine) addHostRoute(host *hostRouter, method, path string, handlers HandlersChain) {
 	assert1(path[0] == '/', "path must begin with '/'")
 	assert1(method != "", "HTTP method can not be empty")
 	assert1(len(handlers) > 0, "there must be at least one handler")
 
 	debugPrintRoute(method, path, handlers)
 
-	root := engine.trees.get(method) // 根节点
+	// 运行时修改过路由之后，路由表已经发布，只能通过复制的方式添加
+	if engine.table.Load() != nil {
+		hostPattern := ""
+		if host != nil {
+			hostPattern = host.pattern
+		}
+		if err := engine.addRuntimeRoute(hostPattern, method, path, handlers); err != nil {
+			panic(err.Error())
+		}
+		return
+	}
+
+	trees := &engine.trees
+	var hostParams uint16
+	if host != nil {
+		trees = &host.trees
+		hostParams = host.params
+	}
+	root := trees.get(method) // 根节点
 	if root == nil {
 		root = new(node)
 		root.fullPath = "/"
-		engine.trees = append(engine.trees, methodTree{method: method, root: root})
+		*trees = append(*trees, methodTree{method: method, root: root})
 	}
 	root.addRoute(path, handlers) // 新增路由
 
 	// Update maxParams
-	if paramsCount := countParams(path); paramsCount > engine.maxParams {
+	if paramsCount := countParams(path) + hostParams; paramsCount > engine.maxParams {
 		engine.maxParams = paramsCount // 设置 maxParams
 	}
 
@@ -367,25 +447,43 @@ func (engine *Engine) addRoute(method, path string, handlers HandlersChain) {
 // 返回一个路由列表信息RoutesInfo(一个路由信息RouteInfo中包含Method,Path,Handler,HandlerFunc)
 // 该方法底层调用engine的trees来获取一些router必要的信息
 func (engine *Engine) Routes() (routes RoutesInfo) {
-	for _, tree := range engine.trees {
-		routes = iterate("", tree.method, routes, tree.root)
+	trees, hosts := engine.routing()
+	for _, tree := range trees {
+		routes = iterate(tree.method, routes, tree.root)
+	}
+	for _, host := range hosts {
+		start := len(routes)
+		for _, tree := range host.trees {
+			routes = iterate(tree.method, routes, tree.root)
+		}
+		for i := start; i < len(routes); i++ {
+			routes[i].Host = host.pattern
+		}
 	}
+	engine.routesMu.Lock()
+	for i := range routes {
+		routes[i].Name = engine.routeName(routes[i].Host, routes[i].Method, routes[i].Path)
+		routes[i].Doc = engine.routeDoc(routes[i].Host, routes[i].Method, routes[i].Path)
+	}
+	engine.routesMu.Unlock()
 	return routes
 }
 
-func iterate(path, method string, routes RoutesInfo, root *node) RoutesInfo {
-	path += root.path
+// iterate 收集 root 下所有注册了 handlers 的路由，Path 使用注册时的完整路径，
+// {id:int} 这样的约束参数在树中被转换为了 :id，拼接节点路径得不到原始路径
+func iterate(method string, routes RoutesInfo, root *node) RoutesInfo {
 	if len(root.handlers) > 0 {
 		handlerFunc := root.handlers.Last()
 		routes = append(routes, RouteInfo{
 			Method:      method,
-			Path:        path,
+			Path:        root.fullPath,
 			Handler:     nameOfFunction(handlerFunc),
 			HandlerFunc: handlerFunc,
+			Middlewares: len(root.handlers) - 1,
 		})
 	}
 	for _, child := range root.children {
-		routes = iterate(path, method, routes, child)
+		routes = iterate(method, routes, child)
 	}
 	return routes
 }
@@ -600,12 +698,6 @@ func (engine *Engine) RunListener(listener net.Listener) (err error) {
 // ServeHTTP conforms to the http.Handler interface.
 // 遵循http.Handler的接口规范，可使gin内部调用http.ListenAndServe来启动一个http服务
 func (engine *Engine) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	container := engine.container.(*framework.HttpgoContainer)
-	providers := container.PrintProviders()
-	for i, provider := range providers {
-		fmt.Println(i, provider)
-	}
-
 	c := engine.pool.Get().(*Context) // 从sync.pool中获取 *Context
 	c.writermem.reset(w)              // 初始化ResponseWriter内存，防止数据污染
 	c.Request = req                   // 设置 Ctx 的 Request
@@ -642,8 +734,15 @@ func (engine *Engine) handleHTTPRequest(c *Context) {
 		rPath = cleanPath(rPath) // clean path
 	}
 
+	// 请求的 host 匹配 Host 注册的 host 模式时，使用该 host 的路由树
+	t, hosts := engine.routing()
+	engine.ensureParamsCap(c)
+	host, hostName := matchHost(hosts, c.Request.Host)
+	if host != nil {
+		t = host.trees
+	}
+
 	// Find root of the tree for the given HTTP method
-	t := engine.trees
 	for i, tl := 0, len(t); i < tl; i++ {
 		if t[i].method != httpMethod {
 			continue
@@ -655,6 +754,9 @@ func (engine *Engine) handleHTTPRequest(c *Context) {
 			c.Params = *value.params // 参数
 		}
 		if value.handlers != nil {
+			if host != nil && host.params > 0 {
+				c.Params = host.appendParams(hostName, c.Params) // host 中的参数排在路径参数之后
+			}
 			c.handlers = value.handlers
 			c.fullPath = value.fullPath
 			c.Next()                     // 调用 handlers，开始对请求执行中间件和处理函数
@@ -677,16 +779,18 @@ func (engine *Engine) handleHTTPRequest(c *Context) {
 		break
 	}
 	// 没有路由
+	if httpMethod == http.MethodHead && engine.HandleHEAD && engine.serveHEAD(c, t, rPath, unescape, host, hostName) {
+		return // 使用 GET 路由响应 HEAD 请求
+	}
+	if httpMethod == http.MethodOptions && engine.HandleOPTIONS && engine.serveOPTIONS(c, t, rPath, unescape) {
+		return // 自动响应 OPTIONS 请求
+	}
 	if engine.HandleMethodNotAllowed { // 启用了 HandleMethodNotAllowed，来处理 405
-		for _, tree := range engine.trees {
-			if tree.method == httpMethod {
-				continue
-			}
-			if value := tree.root.getValue(rPath, nil, c.skippedNodes, unescape); value.handlers != nil {
-				c.handlers = engine.allNoMethod //	调用它的 ServeHTTP 方法来响应请求，结束
-				serveError(c, http.StatusMethodNotAllowed, default405Body)
-				return
-			}
+		if allow := engine.allowedMethods(c, t, rPath, unescape); allow != "" {
+			c.writermem.Header().Set("Allow", allow)
+			c.handlers = engine.allNoMethod //	调用它的 ServeHTTP 方法来响应请求，结束
+			serveError(c, http.StatusMethodNotAllowed, default405Body)
+			return
 		}
 	}
 	c.handlers = engine.allNoRoute                     // 没有匹配的路由
@@ -702,6 +806,10 @@ func serveError(c *Context, code int, defaultMessage []byte) {
 		return
 	}
 	if c.writermem.Status() == code {
+		if c.problemDetails() {
+			c.Render(code, render.ProblemJSON{Data: NewProblem(code).WithInstance(c.Request.URL.Path)})
+			return
+		}
 		c.writermem.Header()["Content-Type"] = mimePlain
 		_, err := c.Writer.Write(defaultMessage)
 		if err != nil {
@@ -763,7 +871,9 @@ func (engine *Engine) Lookup(method, path string) (HandlersChain, *Params, bool,
 	//	path = c.Request.URL.RawPath
 	//	unescape = engine.UnescapePathValues
 	//}
-	if root := engine.trees.get(method); root != nil {
+	trees, _ := engine.routing()
+	engine.ensureParamsCap(c)
+	if root := trees.get(method); root != nil {
 		//return root.getValue(path)
 		value := root.getValue(path, c.params, c.skippedNodes, unescape)
 		return value.handlers, value.params, value.tsr, value.fullPath
@@ -0,0 +1,216 @@
+package gin
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ParamConstraintFunc 路由参数约束的校验函数，返回 false 表示参数值不满足约束
+type ParamConstraintFunc func(value string) bool
+
+var (
+	paramConstraintsLock sync.RWMutex
+	// paramConstraints 内置和注册的命名约束，{id:int} 中的 int 即为约束名
+	paramConstraints = map[string]ParamConstraintFunc{
+		"int":   isIntParam,
+		"uint":  isUintParam,
+		"alpha": isAlphaParam,
+		"uuid":  isUUIDParam,
+	}
+)
+
+// RegisterParamConstraint 注册一个命名约束，之后可以在路由中以 {name:constraint} 的形式使用
+// 需要在注册路由之前调用
+func RegisterParamConstraint(name string, fn ParamConstraintFunc) {
+	assert1(name != "", "param constraint name can not be empty")
+	assert1(fn != nil, "param constraint func can not be nil")
+	paramConstraintsLock.Lock()
+	defer paramConstraintsLock.Unlock()
+	paramConstraints[name] = fn
+}
+
+// paramConstraint 挂在路由树 param 节点上的约束
+type paramConstraint struct {
+	name  string // 参数名
+	expr  string // 约束表达式：命名约束或者正则表达式
+	match ParamConstraintFunc
+}
+
+// pattern 返回参数在路由中的原始写法，例如：{id:int}
+func (pc *paramConstraint) pattern() string {
+	return "{" + pc.name + ":" + pc.expr + "}"
+}
+
+// constraintPattern 返回 param 节点在路由中的写法，没有约束时为 :name
+func constraintPattern(n *node) string {
+	if n.constraint == nil {
+		return n.path
+	}
+	return n.constraint.pattern()
+}
+
+// sameConstraint 两个参数节点的约束是否相同
+func sameConstraint(a, b *paramConstraint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.expr == b.expr
+}
+
+// newParamConstraint 根据表达式创建约束，优先使用命名约束，否则作为正则表达式整体匹配参数值
+func newParamConstraint(name, expr, fullPath string) *paramConstraint {
+	paramConstraintsLock.RLock()
+	fn, ok := paramConstraints[expr]
+	paramConstraintsLock.RUnlock()
+	if !ok {
+		re, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			panic("invalid constraint '" + expr + "' for param '" + name + "' in path '" + fullPath + "': " + err.Error())
+		}
+		fn = re.MatchString
+	}
+	return &paramConstraint{name: name, expr: expr, match: fn}
+}
+
+// parseParamConstraints 把路由中的 {name} 和 {name:constraint} 转换为路由树使用的 :name
+// 返回转换后的路径和参数名对应的约束，没有约束的参数不在返回的 map 中
+// 例如：/user/{id:int}/{slug:[a-z-]+} => /user/:id/:slug
+func parseParamConstraints(path string) (string, map[string]*paramConstraint) {
+	if strings.IndexByte(path, '{') < 0 {
+		return path, nil
+	}
+
+	var constraints map[string]*paramConstraint
+	var sb strings.Builder
+	sb.Grow(len(path))
+	for i := 0; i < len(path); i++ {
+		if path[i] != '{' {
+			sb.WriteByte(path[i])
+			continue
+		}
+		end := matchingBrace(path, i)
+		if end < 0 {
+			panic("unclosed '{' in path '" + path + "'")
+		}
+		// 约束只能占据一个完整的路径段
+		if i == 0 || path[i-1] != '/' || (end+1 < len(path) && path[end+1] != '/') {
+			panic("param constraint must be a whole path segment in path '" + path + "'")
+		}
+		name, expr, hasExpr := strings.Cut(path[i+1:end], ":")
+		if name == "" || strings.ContainsAny(name, ":*/{}") {
+			panic("invalid param name '" + name + "' in path '" + path + "'")
+		}
+		sb.WriteByte(':')
+		sb.WriteString(name)
+		if hasExpr {
+			if expr == "" {
+				panic("empty constraint for param '" + name + "' in path '" + path + "'")
+			}
+			if constraints == nil {
+				constraints = make(map[string]*paramConstraint)
+			}
+			if _, ok := constraints[name]; ok {
+				panic("duplicate param '" + name + "' in path '" + path + "'")
+			}
+			constraints[name] = newParamConstraint(name, expr, path)
+		}
+		i = end
+	}
+	return sb.String(), constraints
+}
+
+// fullPathPrefix 把树中路径（{id:int} 已转换为 :id）的前 n 个字节对应到带约束的 fullPath 上，返回 fullPath 的前缀
+func fullPathPrefix(fullPath string, n int) string {
+	if strings.IndexByte(fullPath, '{') < 0 {
+		return fullPath[:n]
+	}
+	i, j := 0, 0
+	for j < n && i < len(fullPath) {
+		if fullPath[i] != '{' {
+			i++
+			j++
+			continue
+		}
+		end := matchingBrace(fullPath, i)
+		name, _, _ := strings.Cut(fullPath[i+1:end], ":")
+		// 树中对应的是 ':' + name
+		if seg := 1 + len(name); j+seg <= n {
+			i, j = end+1, j+seg
+			continue
+		}
+		return fullPath[:i+n-j]
+	}
+	return fullPath[:i]
+}
+
+// matchingBrace 返回和 path[start] 处的 '{' 配对的 '}' 的位置，正则中的 {n,m} 会被跳过
+func matchingBrace(path string, start int) int {
+	depth := 0
+	for i := start; i < len(path); i++ {
+		switch path[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func isIntParam(s string) bool {
+	if len(s) > 0 && (s[0] == '-' || s[0] == '+') {
+		s = s[1:]
+	}
+	return isUintParam(s)
+}
+
+func isUintParam(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlphaParam(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i] | 0x20; c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// isUUIDParam 校验 8-4-4-4-12 格式的 uuid，不区分大小写
+func isUUIDParam(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			c := s[i]
+			if !('0' <= c && c <= '9' || 'a' <= c|0x20 && c|0x20 <= 'f') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,129 @@
+package gin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseParamConstraints(t *testing.T) {
+	path, constraints := parseParamConstraints("/book/:id")
+	assert.Equal(t, "/book/:id", path)
+	assert.Nil(t, constraints)
+
+	path, constraints = parseParamConstraints("/user/{id:int}/{name}/post/{slug:[a-z]{2,8}}/*file")
+	assert.Equal(t, "/user/:id/:name/post/:slug/*file", path)
+	assert.Len(t, constraints, 2)
+	assert.Equal(t, "{id:int}", constraints["id"].pattern())
+	assert.Equal(t, "{slug:[a-z]{2,8}}", constraints["slug"].pattern())
+
+	assert.Panics(t, func() { parseParamConstraints("/user/{id:int") })
+	assert.Panics(t, func() { parseParamConstraints("/user/x{id:int}") })
+	assert.Panics(t, func() { parseParamConstraints("/user/{id:int}x") })
+	assert.Panics(t, func() { parseParamConstraints("/user/{:int}") })
+	assert.Panics(t, func() { parseParamConstraints("/user/{id:}") })
+	assert.Panics(t, func() { parseParamConstraints("/user/{id:[a-z}") })
+	assert.Panics(t, func() { parseParamConstraints("/user/{id:int}/{id:alpha}") })
+}
+
+func TestBuiltinParamConstraints(t *testing.T) {
+	assert.True(t, isIntParam("-12"))
+	assert.False(t, isIntParam("-"))
+	assert.True(t, isUintParam("012"))
+	assert.False(t, isUintParam("+12"))
+	assert.True(t, isAlphaParam("abcXYZ"))
+	assert.False(t, isAlphaParam("ab1"))
+	assert.True(t, isUUIDParam("123e4567-E89B-12d3-a456-426614174000"))
+	assert.False(t, isUUIDParam("123e4567-e89b-12d3-a456-42661417400g"))
+	assert.False(t, isUUIDParam("123e4567e89b-12d3-a456-4266141740000"))
+}
+
+func TestTreeParamConstraints(t *testing.T) {
+	RegisterParamConstraint("even", func(s string) bool {
+		return isUintParam(s) && (s[len(s)-1]-'0')%2 == 0
+	})
+
+	tree := &node{}
+	routes := [...]string{
+		"/user/me",
+		"/user/{id:int}",
+		"/user/{id:int}/posts",
+		"/book/{slug:[a-z-]+}",
+		"/order/{uuid:uuid}",
+		"/page/{n:even}",
+		"/file/{name}",
+	}
+	for _, route := range routes {
+		tree.addRoute(route, fakeHandler(route))
+	}
+
+	checkRequests(t, tree, testRequests{
+		{"/user/me", false, "/user/me", nil},
+		{"/user/12", false, "/user/{id:int}", Params{Param{"id", "12"}}},
+		{"/user/mex", true, "", nil},
+		{"/user/abc", true, "", nil},
+		{"/user/12/posts", false, "/user/{id:int}/posts", Params{Param{"id", "12"}}},
+		{"/user/abc/posts", true, "", nil},
+		{"/book/go-in-action", false, "/book/{slug:[a-z-]+}", Params{Param{"slug", "go-in-action"}}},
+		{"/book/Go", true, "", nil},
+		{"/order/123e4567-e89b-12d3-a456-426614174000", false, "/order/{uuid:uuid}", Params{Param{"uuid", "123e4567-e89b-12d3-a456-426614174000"}}},
+		{"/order/123", true, "", nil},
+		{"/page/4", false, "/page/{n:even}", Params{Param{"n", "4"}}},
+		{"/page/3", true, "", nil},
+		{"/file/a.txt", false, "/file/{name}", Params{Param{"name", "a.txt"}}},
+	})
+
+	checkPriorities(t, tree)
+
+	out, found := tree.findCaseInsensitivePath("/USER/12", true)
+	assert.True(t, found)
+	assert.Equal(t, "/user/12", string(out))
+	_, found = tree.findCaseInsensitivePath("/USER/abc/POSTS", true)
+	assert.False(t, found)
+}
+
+func TestTreeParamConstraintSplitFullPath(t *testing.T) {
+	tree := &node{}
+	for _, route := range []string{"/user/{id:int}/posts", "/user/{id:int}/photos"} {
+		tree.addRoute(route, fakeHandler(route))
+	}
+
+	// 约束参数之后的公共前缀 /p 被拆分出来，它的 fullPath 要保留完整的约束
+	split := tree
+	for split.path != "/p" {
+		assert.Len(t, split.children, 1)
+		split = split.children[0]
+	}
+	assert.Equal(t, "/user/{id:int}/p", split.fullPath)
+
+	assert.Equal(t, "/user/{id:int}/p", fullPathPrefix("/user/{id:int}/posts", len("/user/:id/p")))
+	assert.Equal(t, "/user/{i", fullPathPrefix("/user/{id:int}/posts", len("/user/:i")))
+	assert.Equal(t, "/user/", fullPathPrefix("/user/{id:int}/posts", len("/user/")))
+	assert.Equal(t, "/user/:id/p", fullPathPrefix("/user/:id/posts", len("/user/:id/p")))
+
+	checkRequests(t, tree, testRequests{
+		{"/user/12/posts", false, "/user/{id:int}/posts", Params{Param{"id", "12"}}},
+		{"/user/12/photos", false, "/user/{id:int}/photos", Params{Param{"id", "12"}}},
+	})
+}
+
+func TestTreeParamConstraintConflict(t *testing.T) {
+	routes := []testRoute{
+		{"/user/{id:int}", false},
+		{"/user/{id:int}/posts", false},
+		{"/user/:id/likes", true},
+		{"/user/{id:uuid}/files", true},
+		{"/user/{name:alpha}", true},
+		{"/user/me", false},
+	}
+	testRoutes(t, routes)
+}
+
+func TestRouteURLWithParamConstraints(t *testing.T) {
+	router := New()
+	router.GET("/user/{id:int}/{slug:[a-z-]+}", func(c *Context) {}).Name("user.post")
+
+	url, err := router.URL("user.post", 12, "hello-world")
+	assert.NoError(t, err)
+	assert.Equal(t, "/user/12/hello-world", url)
+}
@@ -0,0 +1,40 @@
+package gin
+
+// RouteDoc 路由的文档信息，用于生成 OpenAPI 文档
+type RouteDoc struct {
+	Summary     string
+	Description string
+	Tags        []string
+	// Security 需要的安全方案名，例如：bearer
+	Security []string
+	// Request 请求参数的类型，使用结构体的零值，按 uri/form/header/json 标签生成参数和请求体
+	Request interface{}
+	// Responses 状态码对应的响应体类型，值为 nil 表示没有响应体
+	Responses  map[int]interface{}
+	Deprecated bool
+}
+
+// Doc 给最近一次注册的路由添加文档信息，和 Name 的调用顺序无关，例如：
+// r.GET("/demo/:id", handler).Name("demo.show").Doc(gin.RouteDoc{Summary: "详情"})
+func (group *RouterGroup) Doc(doc RouteDoc) IRoutes {
+	group.engine.docRoute(doc)
+	return group.returnObj()
+}
+
+// docRoute 记录最近一次注册的路由的文档信息
+func (engine *Engine) docRoute(doc RouteDoc) {
+	engine.routesMu.Lock()
+	defer engine.routesMu.Unlock()
+	assert1(len(engine.lastRoutes) > 0, "route doc must follow a route registration")
+	if engine.routeDocs == nil {
+		engine.routeDocs = make(map[routeKey]*RouteDoc)
+	}
+	for _, route := range engine.lastRoutes {
+		engine.routeDocs[route] = &doc
+	}
+}
+
+// routeDoc 返回路由的文档信息，没有时返回 nil，需要持有 routesMu
+func (engine *Engine) routeDoc(host, method, path string) *RouteDoc {
+	return engine.routeDocs[routeKey{host: host, method: method, path: path}]
+}
@@ -0,0 +1,28 @@
+package gin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteDoc(t *testing.T) {
+	router := New()
+	handler := func(c *Context) {}
+	router.GET("/book/:id", handler).Doc(RouteDoc{Summary: "book", Tags: []string{"book"}}).Name("book.show")
+	router.Match([]string{http.MethodPut, http.MethodPatch}, "/book/:id", handler).Doc(RouteDoc{Summary: "update"})
+	router.POST("/book", handler)
+
+	docs := map[string]*RouteDoc{}
+	for _, route := range router.Routes() {
+		docs[route.Method+" "+route.Path] = route.Doc
+	}
+	assert.Equal(t, "book", docs["GET /book/:id"].Summary)
+	assert.Equal(t, []string{"book"}, docs["GET /book/:id"].Tags)
+	assert.Equal(t, "update", docs["PUT /book/:id"].Summary)
+	assert.Same(t, docs["PUT /book/:id"], docs["PATCH /book/:id"])
+	assert.Nil(t, docs["POST /book"])
+
+	assert.Panics(t, func() { New().Doc(RouteDoc{}) })
+}
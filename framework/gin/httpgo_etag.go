@@ -0,0 +1,201 @@
+package gin
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// defaultETagBufferSize ETag 中间件默认缓存的最大响应体
+const defaultETagBufferSize = 1 << 20 // 1 MB
+
+// ErrPreconditionFailed If-Match 或者 If-None-Match 条件不满足
+var ErrPreconditionFailed = RegisterError(41290, http.StatusPreconditionFailed, "precondition failed")
+
+// ETagOptions ETag 中间件的选项
+type ETagOptions struct {
+	// Weak 生成弱 ETag（W/"..."），响应在语义上等价但字节可能不同时使用
+	Weak bool
+	// MaxBufferSize 缓存的最大响应体字节数，超过时或者处理函数调用了 Flush 时直接输出，不计算 ETag，默认 1MB
+	MaxBufferSize int
+}
+
+// ETag 中间件，缓存 GET 和 HEAD 请求的 200 响应，根据响应体计算 ETag，处理函数已经设置了 ETag 时使用处理函数的
+// 之后与 Context.File 一样通过 http.ServeContent 输出，支持 If-None-Match、If-Modified-Since 返回 304 以及 Range 请求
+// 其他方法的 If-Match 需要在修改资源之前知道当前的 ETag，由处理函数调用 CheckETag 处理
+func ETag(opts ...ETagOptions) HandlerFunc {
+	var opt ETagOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.MaxBufferSize <= 0 {
+		opt.MaxBufferSize = defaultETagBufferSize
+	}
+	return func(c *Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+		w := &etagResponseWriter{ResponseWriter: c.writermem.ResponseWriter, status: http.StatusOK, limit: opt.MaxBufferSize}
+		c.writermem.ResponseWriter = w
+		defer func() { c.writermem.ResponseWriter = w.ResponseWriter }()
+		c.Next()
+		c.writermem.WriteHeaderNow()
+		w.finish(c.Request, opt.Weak)
+		c.writermem.status = w.status
+	}
+}
+
+// CheckETag 设置响应的 ETag 并处理条件请求，etag 可以由资源的版本号、更新时间等低成本地生成，不需要先生成响应体
+// GET 和 HEAD 请求的 If-None-Match 匹配时返回 304；其他请求 If-None-Match 匹配或者 If-Match 不匹配时返回 412
+// 返回 true 表示已经写入了响应，处理函数应该直接返回：
+//
+//	if c.CheckETag(gin.StrongETag(strconv.Itoa(user.Version))) {
+//		return
+//	}
+func (c *Context) CheckETag(etag string) bool {
+	etag = formatETag(etag)
+	c.Header("ETag", etag)
+	if match := c.GetHeader("If-Match"); match != "" && !etagListMatch(match, etag, true) {
+		c.Fail(ErrPreconditionFailed)
+		return true
+	}
+	if noneMatch := c.GetHeader("If-None-Match"); noneMatch != "" && etagListMatch(noneMatch, etag, false) {
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			c.AbortWithStatus(http.StatusNotModified)
+		} else {
+			c.Fail(ErrPreconditionFailed)
+		}
+		return true
+	}
+	return false
+}
+
+// StrongETag 返回强 ETag，即加上双引号的 version
+func StrongETag(version string) string {
+	return `"` + version + `"`
+}
+
+// WeakETag 返回弱 ETag，即 W/ 加上双引号的 version
+func WeakETag(version string) string {
+	return `W/"` + version + `"`
+}
+
+// formatETag 没有双引号的 etag 作为强 ETag 处理
+func formatETag(etag string) string {
+	if strings.HasPrefix(etag, `"`) || strings.HasPrefix(etag, `W/"`) {
+		return etag
+	}
+	return StrongETag(etag)
+}
+
+// etagListMatch header 是否匹配 etag，header 为 "*" 或者逗号分隔的 ETag 列表
+// strong 为 true 时使用强比较，弱 ETag 不匹配任何 ETag，见 RFC 7232 2.3.2
+func etagListMatch(header, etag string, strong bool) bool {
+	if strong && strings.HasPrefix(etag, "W/") {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strong {
+			if candidate == etag {
+				return true
+			}
+			continue
+		}
+		if strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// etagResponseWriter 缓存响应头和响应体，直到 finish 时输出
+// 响应体超过 limit 或者调用了 Flush 时放弃缓存，之后直接写入原始的 ResponseWriter
+type etagResponseWriter struct {
+	http.ResponseWriter
+	buf         bytes.Buffer
+	limit       int
+	status      int
+	passthrough bool
+	wroteHeader bool
+}
+
+func (w *etagResponseWriter) WriteHeader(code int) {
+	w.status = code
+	if w.passthrough && !w.wroteHeader {
+		w.wroteHeader = true
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *etagResponseWriter) Write(data []byte) (int, error) {
+	if !w.passthrough {
+		if w.buf.Len()+len(data) <= w.limit {
+			return w.buf.Write(data)
+		}
+		w.stream()
+	}
+	if !w.wroteHeader {
+		w.WriteHeader(w.status)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *etagResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush 流式响应不计算 ETag
+func (w *etagResponseWriter) Flush() {
+	if !w.passthrough {
+		w.stream()
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack 透传给原始的 ResponseWriter
+func (w *etagResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// stream 放弃缓存，写入响应头和已经缓存的响应体
+func (w *etagResponseWriter) stream() {
+	w.passthrough = true
+	w.WriteHeader(w.status)
+	if w.buf.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.buf.Bytes())
+		w.buf.Reset()
+	}
+}
+
+// finish 输出缓存的响应，200 响应通过 http.ServeContent 处理条件请求和 Range 请求
+func (w *etagResponseWriter) finish(req *http.Request, weak bool) {
+	if w.passthrough {
+		return
+	}
+	if w.status != http.StatusOK {
+		w.stream()
+		return
+	}
+	header := w.Header()
+	if header.Get("ETag") == "" {
+		sum := sha256.Sum256(w.buf.Bytes())
+		version := hex.EncodeToString(sum[:16])
+		if weak {
+			header.Set("ETag", WeakETag(version))
+		} else {
+			header.Set("ETag", StrongETag(version))
+		}
+	}
+	modtime, _ := http.ParseTime(header.Get("Last-Modified"))
+	w.passthrough = true
+	http.ServeContent(w, req, "", modtime, bytes.NewReader(w.buf.Bytes()))
+}
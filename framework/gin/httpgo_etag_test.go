@@ -0,0 +1,133 @@
+package gin
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestETagMiddleware(t *testing.T) {
+	var status int
+	router := New()
+	router.Use(func(c *Context) {
+		c.Next()
+		status = c.Writer.Status()
+	})
+	router.Use(ETag())
+	router.GET("/json", func(c *Context) {
+		c.JSON(http.StatusOK, H{"name": "httpgo"})
+	})
+	router.GET("/data", func(c *Context) {
+		c.Data(http.StatusOK, "text/plain", []byte("0123456789"))
+	})
+	router.GET("/missing", func(c *Context) {
+		c.String(http.StatusNotFound, "missing")
+	})
+	router.GET("/stream", func(c *Context) {
+		c.String(http.StatusOK, "chunk")
+		c.Writer.Flush()
+	})
+
+	w := PerformRequest(router, http.MethodGet, "/json")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"name":"httpgo"}`, w.Body.String())
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	etag := w.Header().Get("ETag")
+	assert.Len(t, etag, 34)
+
+	w = PerformRequest(router, http.MethodGet, "/json", header{"If-None-Match", `"other", ` + etag})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+	assert.Equal(t, http.StatusNotModified, status)
+
+	w = PerformRequest(router, http.MethodGet, "/data", header{"Range", "bytes=2-4"})
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "234", w.Body.String())
+	assert.Equal(t, "bytes 2-4/10", w.Header().Get("Content-Range"))
+
+	router.HandleHEAD = true
+	w = PerformRequest(router, http.MethodHead, "/data")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, "10", w.Header().Get("Content-Length"))
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+
+	w = PerformRequest(router, http.MethodGet, "/missing")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "missing", w.Body.String())
+	assert.Empty(t, w.Header().Get("ETag"))
+
+	w = PerformRequest(router, http.MethodGet, "/stream")
+	assert.Equal(t, "chunk", w.Body.String())
+	assert.Empty(t, w.Header().Get("ETag"))
+	assert.True(t, w.Flushed)
+}
+
+func TestETagMiddlewareOptions(t *testing.T) {
+	router := New()
+	router.GET("/weak", ETag(ETagOptions{Weak: true}), func(c *Context) {
+		c.String(http.StatusOK, "weak")
+	})
+	router.GET("/large", ETag(ETagOptions{MaxBufferSize: 4}), func(c *Context) {
+		c.String(http.StatusOK, "larger than four")
+	})
+
+	w := PerformRequest(router, http.MethodGet, "/weak")
+	etag := w.Header().Get("ETag")
+	assert.True(t, strings.HasPrefix(etag, `W/"`))
+	// If-None-Match 使用弱比较
+	w = PerformRequest(router, http.MethodGet, "/weak", header{"If-None-Match", strings.TrimPrefix(etag, "W/")})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	w = PerformRequest(router, http.MethodGet, "/large")
+	assert.Equal(t, "larger than four", w.Body.String())
+	assert.Empty(t, w.Header().Get("ETag"))
+}
+
+func TestCheckETag(t *testing.T) {
+	version, rendered := 3, 0
+	router := New()
+	router.Use(ETag())
+	router.GET("/user", func(c *Context) {
+		if c.CheckETag(strconv.Itoa(version)) {
+			return
+		}
+		rendered++
+		c.JSON(http.StatusOK, H{"version": version})
+	})
+	router.PUT("/user", func(c *Context) {
+		if c.CheckETag(StrongETag(strconv.Itoa(version))) {
+			return
+		}
+		version++
+		c.Status(http.StatusNoContent)
+	})
+
+	w := PerformRequest(router, http.MethodGet, "/user")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	w = PerformRequest(router, http.MethodGet, "/user", header{"If-None-Match", `W/"3"`})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, 1, rendered)
+
+	w = PerformRequest(router, http.MethodPut, "/user", header{"If-Match", `"2"`})
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":41290`)
+	// If-Match 使用强比较，弱 ETag 不匹配
+	w = PerformRequest(router, http.MethodPut, "/user", header{"If-Match", `W/"3"`})
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	w = PerformRequest(router, http.MethodPut, "/user", header{"If-None-Match", "*"})
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Equal(t, 3, version)
+
+	w = PerformRequest(router, http.MethodPut, "/user", header{"If-Match", `"1", "3"`})
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, 4, version)
+	w = PerformRequest(router, http.MethodPut, "/user", header{"If-Match", "*"})
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, 5, version)
+}
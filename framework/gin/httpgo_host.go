@@ -0,0 +1,172 @@
+package gin
+
+import (
+	"net"
+	"strings"
+)
+
+// hostRouter 一个 host 模式及其独立的路由树
+type hostRouter struct {
+	pattern string      // 注册时的 host 模式，例如：{tenant}.example.com
+	labels  []hostLabel // 按 '.' 切分的 host 模式
+	trees   methodTrees // 该 host 独立的路由树
+	params  uint16      // host 中参数的数量
+	static  bool        // host 模式中不含参数
+}
+
+// hostLabel host 中的一段，name 不为空时为参数段
+type hostLabel struct {
+	value      string
+	name       string
+	constraint *paramConstraint
+}
+
+// Host 创建一个只匹配指定 host 的路由组，host 中的 {name} 和 {name:constraint} 会作为参数，
+// 可以通过 Context.Param 获取，例如：
+// tenant := r.Host("{tenant}.example.com")
+// tenant.GET("/profile", handler) => c.Param("tenant")
+// 每个 host 有独立的路由树，请求的 host 匹配某个 host 模式后只在它的路由树中查找，
+// 不含参数的 host 优先于含参数的 host 匹配，都不匹配时使用默认的路由树
+func (engine *Engine) Host(pattern string, handlers ...HandlerFunc) *RouterGroup {
+	return &RouterGroup{
+		Handlers: engine.combineHandlers(handlers),
+		basePath: "/",
+		engine:   engine,
+		host:     engine.hostRouter(pattern),
+	}
+}
+
+// hostRouter 返回 host 模式对应的 hostRouter，不存在时创建
+func (engine *Engine) hostRouter(pattern string) *hostRouter {
+	if hr := findHostRouter(engine.hosts, pattern); hr != nil {
+		return hr
+	}
+	hr := newHostRouter(pattern)
+	engine.hosts = insertHostRouter(engine.hosts, hr)
+	return hr
+}
+
+// findHostRouter 在 hosts 中查找 host 模式对应的 hostRouter
+func findHostRouter(hosts []*hostRouter, pattern string) *hostRouter {
+	for _, hr := range hosts {
+		if hr.pattern == pattern {
+			return hr
+		}
+	}
+	return nil
+}
+
+// insertHostRouter 把 hr 插入到 hosts 中，不含参数的 host 排在前面，优先匹配
+func insertHostRouter(hosts []*hostRouter, hr *hostRouter) []*hostRouter {
+	i := len(hosts)
+	if hr.static {
+		for i = 0; i < len(hosts) && hosts[i].static; i++ {
+		}
+	}
+	hosts = append(hosts, nil)
+	copy(hosts[i+1:], hosts[i:])
+	hosts[i] = hr
+	return hosts
+}
+
+// newHostRouter 解析 host 模式
+func newHostRouter(pattern string) *hostRouter {
+	assert1(pattern != "", "host pattern can not be empty")
+	hr := &hostRouter{pattern: pattern, static: true}
+	for _, label := range strings.Split(pattern, ".") {
+		if label == "" {
+			panic("empty label in host pattern '" + pattern + "'")
+		}
+		if label[0] != '{' {
+			if strings.ContainsAny(label, "{}:*/") {
+				panic("invalid label '" + label + "' in host pattern '" + pattern + "'")
+			}
+			hr.labels = append(hr.labels, hostLabel{value: strings.ToLower(label)})
+			continue
+		}
+		if matchingBrace(label, 0) != len(label)-1 {
+			panic("param must be a whole label in host pattern '" + pattern + "'")
+		}
+		name, expr, hasExpr := strings.Cut(label[1:len(label)-1], ":")
+		if name == "" || strings.ContainsAny(name, ":*/{}.") {
+			panic("invalid param name '" + name + "' in host pattern '" + pattern + "'")
+		}
+		hl := hostLabel{name: name}
+		if hasExpr {
+			if expr == "" {
+				panic("empty constraint for param '" + name + "' in host pattern '" + pattern + "'")
+			}
+			hl.constraint = newParamConstraint(name, expr, pattern)
+		}
+		hr.labels = append(hr.labels, hl)
+		hr.params++
+		hr.static = false
+	}
+	return hr
+}
+
+// match host 是否匹配该 host 模式，host 已去掉端口并转为小写
+func (hr *hostRouter) match(host string) bool {
+	for i, label := range hr.labels {
+		var value string
+		if i == len(hr.labels)-1 {
+			value, host = host, ""
+		} else {
+			end := strings.IndexByte(host, '.')
+			if end < 0 {
+				return false
+			}
+			value, host = host[:end], host[end+1:]
+		}
+		if label.name == "" {
+			if value != label.value {
+				return false
+			}
+			continue
+		}
+		if value == "" || strings.IndexByte(value, '.') >= 0 || (label.constraint != nil && !label.constraint.match(value)) {
+			return false
+		}
+	}
+	return true
+}
+
+// appendParams 把 host 中的参数追加到 params 中，host 需已通过 match
+func (hr *hostRouter) appendParams(host string, params Params) Params {
+	for _, label := range hr.labels {
+		value := host
+		if end := strings.IndexByte(host, '.'); end >= 0 {
+			value, host = host[:end], host[end+1:]
+		}
+		if label.name != "" {
+			params = append(params, Param{Key: label.name, Value: value})
+		}
+	}
+	return params
+}
+
+// hostPattern 返回路由组所属的 host 模式，默认的路由树返回空字符串
+func (group *RouterGroup) hostPattern() string {
+	if group.host == nil {
+		return ""
+	}
+	return group.host.pattern
+}
+
+// matchHost 返回请求的 host 在 hosts 中匹配的 hostRouter 和去掉端口并转为小写的 host，没有匹配时返回 nil
+func matchHost(hosts []*hostRouter, requestHost string) (*hostRouter, string) {
+	if len(hosts) == 0 {
+		return nil, ""
+	}
+	host := requestHost
+	if h, _, err := net.SplitHostPort(requestHost); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, hr := range hosts {
+		if hr.match(host) {
+			return hr, host
+		}
+	}
+	return nil, ""
+}
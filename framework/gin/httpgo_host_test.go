@@ -0,0 +1,76 @@
+package gin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostRouting(t *testing.T) {
+	router := New()
+	router.GET("/profile", func(c *Context) { c.String(http.StatusOK, "default") })
+
+	admin := router.Host("Admin.example.com")
+	admin.GET("/profile", func(c *Context) { c.String(http.StatusOK, "admin") })
+
+	tenant := router.Host("{tenant}.example.com")
+	tenant.GET("/profile", func(c *Context) { c.String(http.StatusOK, "tenant "+c.Param("tenant")) })
+	v1 := tenant.Group("/v1")
+	v1.GET("/book/:id", func(c *Context) { c.String(http.StatusOK, c.Param("tenant")+" "+c.Param("id")) })
+
+	region := router.Host("{tenant}.{region:[a-z]{2}}.example.org")
+	region.GET("/", func(c *Context) { c.String(http.StatusOK, c.Param("tenant")+" "+c.Param("region")) })
+
+	tests := []struct {
+		host string
+		path string
+		code int
+		body string
+	}{
+		{"example.com", "/profile", http.StatusOK, "default"},
+		{"admin.example.com", "/profile", http.StatusOK, "admin"},
+		{"Admin.Example.com:8080", "/profile", http.StatusOK, "admin"},
+		{"acme.example.com", "/profile", http.StatusOK, "tenant acme"},
+		{"acme.example.com", "/v1/book/12", http.StatusOK, "acme 12"},
+		{"admin.example.com", "/v1/book/12", http.StatusNotFound, "404 page not found"},
+		{"a.b.example.com", "/profile", http.StatusOK, "default"},
+		{"acme.eu.example.org", "/", http.StatusOK, "acme eu"},
+		{"acme.eur.example.org", "/", http.StatusNotFound, "404 page not found"},
+	}
+	for _, tt := range tests {
+		w := PerformRequest(router, http.MethodGet, "http://"+tt.host+tt.path)
+		assert.Equal(t, tt.code, w.Code, tt.host+tt.path)
+		assert.Equal(t, tt.body, w.Body.String(), tt.host+tt.path)
+	}
+
+	hosts := map[string]bool{}
+	for _, route := range router.Routes() {
+		hosts[route.Host+" "+route.Path] = true
+	}
+	assert.True(t, hosts[" /profile"])
+	assert.True(t, hosts["Admin.example.com /profile"])
+	assert.True(t, hosts["{tenant}.example.com /v1/book/:id"])
+}
+
+func TestHostMethodNotAllowed(t *testing.T) {
+	router := New()
+	router.HandleMethodNotAllowed = true
+	router.POST("/profile", func(c *Context) {})
+	router.Host("{tenant}.example.com").PUT("/profile", func(c *Context) {})
+
+	w := PerformRequest(router, http.MethodGet, "http://acme.example.com/profile")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	w = PerformRequest(router, http.MethodPost, "http://acme.example.com/profile")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestHostPatternPanics(t *testing.T) {
+	router := New()
+	assert.Panics(t, func() { router.Host("") })
+	assert.Panics(t, func() { router.Host("api..example.com") })
+	assert.Panics(t, func() { router.Host("x{tenant}.example.com") })
+	assert.Panics(t, func() { router.Host("{}.example.com") })
+	assert.Panics(t, func() { router.Host("{tenant:}.example.com") })
+	assert.Same(t, router.Host("{tenant}.example.com").host, router.Host("{tenant}.example.com").host)
+}
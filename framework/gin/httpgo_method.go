@@ -0,0 +1,135 @@
+package gin
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// serveHEAD HEAD 请求没有匹配的路由时，使用同一路径的 GET 路由处理，保留响应头并丢弃响应体
+// 响应头中没有 Content-Length 时，使用 GET 响应体的长度
+func (engine *Engine) serveHEAD(c *Context, trees methodTrees, rPath string, unescape bool, host *hostRouter, hostName string) bool {
+	root := trees.get(http.MethodGet)
+	if root == nil {
+		return false
+	}
+	*c.params = (*c.params)[:0]
+	*c.skippedNodes = (*c.skippedNodes)[:0]
+	value := root.getValue(rPath, c.params, c.skippedNodes, unescape)
+	if value.handlers == nil {
+		return false
+	}
+	c.Params = c.Params[:0]
+	if value.params != nil {
+		c.Params = *value.params
+	}
+	if host != nil && host.params > 0 {
+		c.Params = host.appendParams(hostName, c.Params)
+	}
+
+	w := &headResponseWriter{ResponseWriter: c.writermem.ResponseWriter, status: http.StatusOK}
+	c.writermem.ResponseWriter = w
+	defer func() { c.writermem.ResponseWriter = w.ResponseWriter }()
+	c.handlers = value.handlers
+	c.fullPath = value.fullPath
+	c.Next()
+	c.writermem.WriteHeaderNow()
+	w.finish()
+	return true
+}
+
+// serveOPTIONS OPTIONS 请求没有匹配的路由时，根据路径匹配的所有路由树生成 Allow 响应头
+// 只执行全局中间件（例如 CORS），中间件没有写入响应时返回 204，路径不存在时返回 false
+func (engine *Engine) serveOPTIONS(c *Context, trees methodTrees, rPath string, unescape bool) bool {
+	allow := engine.allowedMethods(c, trees, rPath, unescape)
+	if allow == "" {
+		return false
+	}
+	c.writermem.Header().Set("Allow", allow)
+	c.writermem.status = http.StatusNoContent // 中间件没有写入响应时返回 204
+	c.handlers = engine.Handlers
+	c.Next()
+	c.writermem.WriteHeaderNow()
+	return true
+}
+
+// allowedMethods 返回路径在所有路由树中匹配的请求方法，以逗号分隔，路径为 * 时返回所有注册过的方法
+// 开启了 HandleHEAD 和 HandleOPTIONS 时，会加上自动处理的 HEAD 和 OPTIONS
+func (engine *Engine) allowedMethods(c *Context, trees methodTrees, rPath string, unescape bool) string {
+	methods := make([]string, 0, len(trees)+2)
+	for _, tree := range trees {
+		if rPath != "*" {
+			*c.skippedNodes = (*c.skippedNodes)[:0]
+			if value := tree.root.getValue(rPath, nil, c.skippedNodes, unescape); value.handlers == nil {
+				continue
+			}
+		}
+		methods = append(methods, tree.method)
+	}
+	if len(methods) == 0 {
+		return ""
+	}
+
+	hasMethod := func(method string) bool {
+		for _, m := range methods {
+			if m == method {
+				return true
+			}
+		}
+		return false
+	}
+	if engine.HandleHEAD && hasMethod(http.MethodGet) && !hasMethod(http.MethodHead) {
+		methods = append(methods, http.MethodHead)
+	}
+	if engine.HandleOPTIONS && !hasMethod(http.MethodOptions) {
+		methods = append(methods, http.MethodOptions)
+	}
+	sort.Strings(methods)
+	return strings.Join(methods, ", ")
+}
+
+// headResponseWriter 丢弃响应体，只统计长度，响应头延迟到 finish 时写入以便补充 Content-Length
+type headResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	size        int
+	wroteHeader bool
+}
+
+func (w *headResponseWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *headResponseWriter) Write(data []byte) (int, error) {
+	w.size += len(data)
+	return len(data), nil
+}
+
+func (w *headResponseWriter) WriteString(s string) (int, error) {
+	w.size += len(s)
+	return len(s), nil
+}
+
+// Flush 响应头在 finish 时才写入，这里不需要做任何事
+func (w *headResponseWriter) Flush() {}
+
+// Hijack 透传给原始的 ResponseWriter
+func (w *headResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// finish 写入响应头
+func (w *headResponseWriter) finish() {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	header := w.ResponseWriter.Header()
+	if header.Get("Content-Length") == "" && header.Get("Transfer-Encoding") == "" && w.size > 0 {
+		header.Set("Content-Length", strconv.Itoa(w.size))
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+}
@@ -0,0 +1,77 @@
+package gin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleHEAD(t *testing.T) {
+	router := New()
+	router.GET("/user/:id", func(c *Context) {
+		c.Header("X-User", c.Param("id"))
+		c.String(http.StatusOK, "hello")
+	})
+	router.HEAD("/explicit", func(c *Context) { c.Header("X-Head", "1") })
+	router.GET("/explicit", func(c *Context) { c.String(http.StatusOK, "get") })
+
+	w := PerformRequest(router, http.MethodHead, "/user/12")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	router.HandleHEAD = true
+	w = PerformRequest(router, http.MethodHead, "/user/12")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "12", w.Header().Get("X-User"))
+	assert.Equal(t, "5", w.Header().Get("Content-Length"))
+	assert.Empty(t, w.Body.String())
+
+	w = PerformRequest(router, http.MethodHead, "/explicit")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get("X-Head"))
+
+	w = PerformRequest(router, http.MethodHead, "/missing")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHandleOPTIONS(t *testing.T) {
+	router := New()
+	router.HandleOPTIONS = true
+	router.HandleHEAD = true
+	router.Use(func(c *Context) {
+		c.Header("Access-Control-Allow-Origin", "*")
+		c.Next()
+	})
+	router.GET("/user/:id", func(c *Context) {})
+	router.PUT("/user/:id", func(c *Context) {})
+	router.POST("/user", func(c *Context) {})
+	router.OPTIONS("/custom", func(c *Context) { c.String(http.StatusOK, "custom") })
+
+	w := PerformRequest(router, http.MethodOptions, "/user/12")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "GET, HEAD, OPTIONS, PUT", w.Header().Get("Allow"))
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+
+	w = PerformRequest(router, http.MethodOptions, "/custom")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "custom", w.Body.String())
+
+	w = PerformRequest(router, http.MethodOptions, "*")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "GET, HEAD, OPTIONS, POST, PUT", w.Header().Get("Allow"))
+
+	w = PerformRequest(router, http.MethodOptions, "/missing")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Empty(t, w.Header().Get("Allow"))
+}
+
+func TestMethodNotAllowedAllowHeader(t *testing.T) {
+	router := New()
+	router.HandleMethodNotAllowed = true
+	router.GET("/path", func(c *Context) {})
+	router.POST("/path", func(c *Context) {})
+
+	w := PerformRequest(router, http.MethodDelete, "/path")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "GET, POST", w.Header().Get("Allow"))
+}
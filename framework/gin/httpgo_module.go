@@ -0,0 +1,49 @@
+package gin
+
+import (
+	"fmt"
+
+	"github.com/gothms/httpgo/framework"
+)
+
+// Module 可插拔的业务模块，通过 Engine.RegisterModule 注册
+// 注意：Module 没有 Commands 方法，否则 gin 需要依赖 cobra。模块需要提供控制台命令时，
+// 再实现 command.CommandModule 的 Commands() []*cobra.Command，控制台启动时会把命令添加到根命令中
+type Module interface {
+	// Name 模块名，全局唯一，也是模块路由组的前缀：/name
+	Name() string
+	// Providers 模块需要绑定到容器中的服务提供者
+	Providers() []framework.ServiceProvider
+	// Middlewares 模块路由组的中间件
+	Middlewares() []HandlerFunc
+	// Routes 在模块的路由组中注册路由
+	Routes(group *RouterGroup)
+}
+
+// RegisterModule 注册模块：绑定模块的服务提供者，在 /name 路由组下注册模块的路由，
+// 模块的命令由控制台启动时通过 Modules 获取后添加，见 command.CommandModule
+func (engine *Engine) RegisterModule(module Module) error {
+	name := module.Name()
+	if name == "" {
+		return fmt.Errorf("gin: module name can not be empty")
+	}
+	for _, m := range engine.modules {
+		if m.Name() == name {
+			return fmt.Errorf("gin: module '%s' is already registered", name)
+		}
+	}
+
+	for _, provider := range module.Providers() {
+		if err := engine.Bind(provider); err != nil {
+			return fmt.Errorf("gin: bind provider '%s' of module '%s': %w", provider.Name(), name, err)
+		}
+	}
+	module.Routes(engine.Group("/"+name, module.Middlewares()...))
+	engine.modules = append(engine.modules, module)
+	return nil
+}
+
+// Modules 返回已经注册的模块，按注册的顺序
+func (engine *Engine) Modules() []Module {
+	return engine.modules
+}
@@ -0,0 +1,62 @@
+package gin
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gothms/httpgo/framework"
+	"github.com/stretchr/testify/assert"
+)
+
+type testModuleProvider struct {
+	framework.ServiceProvider
+	bootErr error
+}
+
+func (sp *testModuleProvider) Name() string { return "test:module" }
+func (sp *testModuleProvider) Register(c framework.Container) framework.NewInstance {
+	return func(...interface{}) (interface{}, error) { return "service", nil }
+}
+func (sp *testModuleProvider) IsDefer() bool                              { return false }
+func (sp *testModuleProvider) Params(c framework.Container) []interface{} { return nil }
+func (sp *testModuleProvider) Boot(c framework.Container) error           { return sp.bootErr }
+
+type testModule struct {
+	name     string
+	provider *testModuleProvider
+}
+
+func (m *testModule) Name() string { return m.name }
+func (m *testModule) Providers() []framework.ServiceProvider {
+	if m.provider == nil {
+		return nil
+	}
+	return []framework.ServiceProvider{m.provider}
+}
+func (m *testModule) Middlewares() []HandlerFunc {
+	return []HandlerFunc{func(c *Context) { c.Header("X-Module", m.name) }}
+}
+func (m *testModule) Routes(group *RouterGroup) {
+	group.GET("/ping", func(c *Context) { c.String(http.StatusOK, "pong") })
+}
+
+func TestRegisterModule(t *testing.T) {
+	router := New()
+	container := framework.NewHttpgoContainer()
+	router.SetContainer(container)
+
+	assert.NoError(t, router.RegisterModule(&testModule{name: "blog", provider: &testModuleProvider{}}))
+	assert.True(t, container.IsBind("test:module"))
+	assert.Error(t, router.RegisterModule(&testModule{name: "blog"}))
+	assert.Error(t, router.RegisterModule(&testModule{name: ""}))
+	assert.Error(t, router.RegisterModule(&testModule{name: "shop", provider: &testModuleProvider{bootErr: errors.New("boot")}}))
+
+	w := PerformRequest(router, http.MethodGet, "/blog/ping")
+	assert.Equal(t, "pong", w.Body.String())
+	assert.Equal(t, "blog", w.Header().Get("X-Module"))
+
+	modules := router.Modules()
+	assert.Len(t, modules, 1)
+	assert.Equal(t, "blog", modules[0].Name())
+}
@@ -0,0 +1,150 @@
+package gin
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gothms/httpgo/framework/gin/binding"
+	"github.com/gothms/httpgo/framework/gin/render"
+	"google.golang.org/protobuf/proto"
+)
+
+// MediaRenderer 为 IRespond 生成 obj 的 render.Render，obj 不能以该格式输出时返回 false，例如 protobuf 要求 obj 为 proto.Message
+type MediaRenderer func(obj any) (render.Render, bool)
+
+// mediaRenderers IRespond 支持的媒体类型，types 为注册的顺序，q 值相同时优先使用先注册的类型
+var mediaRenderers = struct {
+	sync.RWMutex
+	types     []string
+	renderers map[string]MediaRenderer
+}{renderers: make(map[string]MediaRenderer)}
+
+func init() {
+	RegisterMediaType(binding.MIMEJSON, func(obj any) (render.Render, bool) { return render.JSON{Data: obj}, true })
+	RegisterMediaType(binding.MIMEXML, func(obj any) (render.Render, bool) { return render.XML{Data: obj}, true })
+	RegisterMediaType(binding.MIMEXML2, func(obj any) (render.Render, bool) { return render.XML{Data: obj}, true })
+	RegisterMediaType(binding.MIMEYAML, func(obj any) (render.Render, bool) { return render.YAML{Data: obj}, true })
+	RegisterMediaType("application/yaml", func(obj any) (render.Render, bool) { return render.YAML{Data: obj}, true })
+	RegisterMediaType(binding.MIMETOML, func(obj any) (render.Render, bool) { return render.TOML{Data: obj}, true })
+	RegisterMediaType(binding.MIMEPROTOBUF, func(obj any) (render.Render, bool) {
+		if _, ok := obj.(proto.Message); !ok {
+			return nil, false
+		}
+		return render.ProtoBuf{Data: obj}, true
+	})
+}
+
+// RegisterMediaType 注册 IRespond 支持的媒体类型，已经注册过的类型会被替换，一般在 init 中调用
+func RegisterMediaType(mediaType string, renderer MediaRenderer) {
+	mediaType = strings.ToLower(mediaType)
+	mediaRenderers.Lock()
+	defer mediaRenderers.Unlock()
+	if _, ok := mediaRenderers.renderers[mediaType]; !ok {
+		mediaRenderers.types = append(mediaRenderers.types, mediaType)
+	}
+	mediaRenderers.renderers[mediaType] = renderer
+}
+
+// MediaTypes 返回 IRespond 支持的媒体类型
+func MediaTypes() []string {
+	mediaRenderers.RLock()
+	defer mediaRenderers.RUnlock()
+	return append([]string(nil), mediaRenderers.types...)
+}
+
+// acceptRange Accept 请求头中的一项
+type acceptRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// matches 返回 mediaType 和 r 匹配的精确度，不匹配时返回 -1：2 为完全匹配，1 为 type/*，0 为 */*
+func (r acceptRange) matches(mediaType string) int {
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+	switch {
+	case r.typ == "*":
+		return 0
+	case r.typ != typ:
+		return -1
+	case r.subtype == "*":
+		return 1
+	case r.subtype == subtype:
+		return 2
+	}
+	return -1
+}
+
+// parseAcceptRanges 解析 Accept 请求头，忽略无法解析的项
+func parseAcceptRanges(accept string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+		typ, subtype, ok := strings.Cut(mediaType, "/")
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, acceptRange{typ: typ, subtype: subtype, q: q})
+	}
+	return ranges
+}
+
+// negotiateMediaType 返回 accept 中 q 值最高的、支持 obj 的媒体类型，q 值相同时使用先注册的类型，Accept 为空时视为 */*
+// 每个媒体类型的 q 值取最精确匹配的一项
+func negotiateMediaType(accept string, obj any) (render.Render, bool) {
+	ranges := parseAcceptRanges(accept)
+	if len(ranges) == 0 {
+		ranges = []acceptRange{{typ: "*", subtype: "*", q: 1}}
+	}
+
+	mediaRenderers.RLock()
+	defer mediaRenderers.RUnlock()
+	var (
+		best  render.Render
+		bestQ float64
+	)
+	for _, mediaType := range mediaRenderers.types {
+		q, precision := 0.0, -1
+		for _, r := range ranges {
+			if p := r.matches(mediaType); p > precision {
+				q, precision = r.q, p
+			}
+		}
+		if q <= bestQ {
+			continue
+		}
+		if r, ok := mediaRenderers.renderers[mediaType](obj); ok {
+			best, bestQ = r, q
+		}
+	}
+	return best, best != nil
+}
+
+// IRespond 根据 Accept 请求头选择输出格式，支持通过 RegisterMediaType 注册的所有媒体类型
+// 没有可以接受的格式时返回 406，响应体中列出支持的媒体类型
+func (ctx *Context) IRespond(obj interface{}) IResponse {
+	ctx.Writer.Header().Add("Vary", "Accept")
+	r, ok := negotiateMediaType(ctx.requestHeader("Accept"), obj)
+	if !ok {
+		_ = ctx.Error(errors.New("the accepted formats are not offered by the server"))
+		ctx.Abort()
+		return ctx.ISetStatus(http.StatusNotAcceptable).
+			IText("not acceptable, supported media types: %s", strings.Join(MediaTypes(), ", "))
+	}
+	return ctx.iRender(r)
+}
@@ -0,0 +1,14 @@
+//go:build !nomsgpack
+
+package gin
+
+import (
+	"github.com/gothms/httpgo/framework/gin/binding"
+	"github.com/gothms/httpgo/framework/gin/render"
+)
+
+func init() {
+	msgpack := func(obj any) (render.Render, bool) { return render.MsgPack{Data: obj}, true }
+	RegisterMediaType(binding.MIMEMSGPACK, msgpack)
+	RegisterMediaType(binding.MIMEMSGPACK2, msgpack)
+}
@@ -0,0 +1,80 @@
+package gin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gothms/httpgo/framework/gin/render"
+	"github.com/gothms/httpgo/framework/gin/testdata/protoexample"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestIRespond(t *testing.T) {
+	router := New()
+	router.GET("/user", func(c *Context) { c.IRespond(H{"name": "foo"}) })
+	label := "test"
+	router.GET("/proto", func(c *Context) { c.IRespond(&protoexample.Test{Label: &label}) })
+
+	tests := []struct {
+		accept      string
+		code        int
+		contentType string
+	}{
+		{"", http.StatusOK, "application/json; charset=utf-8"},
+		{"*/*", http.StatusOK, "application/json; charset=utf-8"},
+		{"application/xml", http.StatusOK, "application/xml; charset=utf-8"},
+		{"text/html, application/xml;q=0.9, */*;q=0.8", http.StatusOK, "application/xml; charset=utf-8"},
+		{"application/json;q=0.5, application/x-yaml", http.StatusOK, "application/x-yaml; charset=utf-8"},
+		{"application/toml", http.StatusOK, "application/toml; charset=utf-8"},
+		{"application/msgpack", http.StatusOK, "application/msgpack; charset=utf-8"},
+		{"application/*;q=0.1, application/json;q=0", http.StatusOK, "application/xml; charset=utf-8"},
+		{"application/x-protobuf", http.StatusNotAcceptable, "text/plain; charset=utf-8"},
+		{"text/html", http.StatusNotAcceptable, "text/plain; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		w := PerformRequest(router, http.MethodGet, "/user", header{"Accept", tt.accept})
+		assert.Equal(t, tt.code, w.Code, tt.accept)
+		assert.Equal(t, tt.contentType, w.Header().Get("Content-Type"), tt.accept)
+		assert.Equal(t, "Accept", w.Header().Get("Vary"))
+	}
+
+	w := PerformRequest(router, http.MethodGet, "/user", header{"Accept", "text/html"})
+	assert.Contains(t, w.Body.String(), "application/json, application/xml")
+
+	w = PerformRequest(router, http.MethodGet, "/proto", header{"Accept", "application/x-protobuf"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	data, _ := proto.Marshal(&protoexample.Test{Label: &label})
+	assert.Equal(t, string(data), w.Body.String())
+}
+
+func TestRegisterMediaType(t *testing.T) {
+	// 恢复全局的媒体类型，避免影响其他测试
+	mediaRenderers.RLock()
+	types := append([]string(nil), mediaRenderers.types...)
+	renderers := make(map[string]MediaRenderer, len(mediaRenderers.renderers))
+	for k, v := range mediaRenderers.renderers {
+		renderers[k] = v
+	}
+	mediaRenderers.RUnlock()
+	t.Cleanup(func() {
+		mediaRenderers.Lock()
+		mediaRenderers.types, mediaRenderers.renderers = types, renderers
+		mediaRenderers.Unlock()
+	})
+
+	RegisterMediaType("text/csv", func(obj any) (render.Render, bool) {
+		rows, ok := obj.([]string)
+		if !ok {
+			return nil, false
+		}
+		return render.Data{ContentType: "text/csv", Data: []byte(rows[0])}, true
+	})
+	assert.Contains(t, MediaTypes(), "text/csv")
+
+	router := New()
+	router.GET("/csv", func(c *Context) { c.IRespond([]string{"a,b"}) })
+	w := PerformRequest(router, http.MethodGet, "/csv", header{"Accept", "text/csv"})
+	assert.Equal(t, "a,b", w.Body.String())
+	assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+}
@@ -0,0 +1,103 @@
+package gin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gothms/httpgo/framework/gin/render"
+)
+
+// Problem RFC 7807 定义的 application/problem+json 错误响应
+type Problem struct {
+	// Type 错误类型的 URI，默认为 about:blank
+	Type string `json:"type,omitempty"`
+	// Title 错误类型的简短描述，Type 为 about:blank 时为状态码的描述
+	Title string `json:"title,omitempty"`
+	// Status HTTP 状态码
+	Status int `json:"status,omitempty"`
+	// Detail 本次错误的具体描述
+	Detail string `json:"detail,omitempty"`
+	// Instance 本次错误的 URI，AbortWithProblem 时默认为请求路径
+	Instance string `json:"instance,omitempty"`
+	// Extensions 扩展字段，和上面的字段在同一层输出，不能覆盖上面的字段
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// NewProblem 返回状态码为 status 的 Problem
+func NewProblem(status int) *Problem {
+	return &Problem{Type: "about:blank", Title: http.StatusText(status), Status: status}
+}
+
+// WithType 设置错误类型和描述
+func (p *Problem) WithType(typ, title string) *Problem {
+	p.Type = typ
+	p.Title = title
+	return p
+}
+
+// WithDetail 设置本次错误的具体描述
+func (p *Problem) WithDetail(detail string) *Problem {
+	p.Detail = detail
+	return p
+}
+
+// WithInstance 设置本次错误的 URI
+func (p *Problem) WithInstance(instance string) *Problem {
+	p.Instance = instance
+	return p
+}
+
+// With 设置扩展字段
+func (p *Problem) With(key string, value interface{}) *Problem {
+	if p.Extensions == nil {
+		p.Extensions = make(map[string]interface{})
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return p.Title + ": " + p.Detail
+	}
+	return p.Title
+}
+
+// MarshalJSON 把扩展字段和标准字段输出在同一层
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	type problem Problem
+	data, err := json.Marshal((*problem)(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return data, err
+	}
+	members := make(map[string]json.RawMessage, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		members[k] = raw
+	}
+	standard := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &standard); err != nil {
+		return nil, err
+	}
+	for k, v := range standard {
+		members[k] = v
+	}
+	return json.Marshal(members)
+}
+
+// AbortWithProblem 终止后续的处理函数，并以 application/problem+json 格式输出 p
+func (c *Context) AbortWithProblem(p *Problem) {
+	if p.Instance == "" && c.Request != nil {
+		p.Instance = c.Request.URL.Path
+	}
+	c.Abort()
+	c.Render(p.Status, render.ProblemJSON{Data: p})
+}
+
+// problemDetails 是否开启了 Engine.ProblemDetails
+func (c *Context) problemDetails() bool {
+	return c.engine != nil && c.engine.ProblemDetails
+}
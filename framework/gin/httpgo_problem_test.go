@@ -0,0 +1,81 @@
+package gin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProblemMarshalJSON(t *testing.T) {
+	p := NewProblem(http.StatusForbidden).
+		WithType("https://example.com/probs/out-of-credit", "You do not have enough credit.").
+		WithDetail("Your current balance is 30, but that costs 50.").
+		WithInstance("/account/12345/msgs/abc").
+		With("balance", 30).
+		With("status", 200) // 不能覆盖标准字段
+	data, err := json.Marshal(p)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "https://example.com/probs/out-of-credit",
+		"title": "You do not have enough credit.",
+		"status": 403,
+		"detail": "Your current balance is 30, but that costs 50.",
+		"instance": "/account/12345/msgs/abc",
+		"balance": 30
+	}`, string(data))
+	assert.Equal(t, "You do not have enough credit.: Your current balance is 30, but that costs 50.", p.Error())
+}
+
+func TestProblemDetails(t *testing.T) {
+	assert.NoError(t, UseErrorTranslator("en", "zh"))
+	router := New()
+	router.ProblemDetails = true
+	router.HandleMethodNotAllowed = true
+	router.Use(RecoveryWithWriter(nil))
+	router.GET("/panic", func(c *Context) { panic("oops") })
+	router.GET("/fail", func(c *Context) { c.Fail(ErrForbidden) })
+	router.POST("/bind", func(c *Context) {
+		var obj struct {
+			Name string `json:"name" binding:"required"`
+		}
+		_ = c.BindJSON(&obj)
+	})
+	router.POST("/validate", func(c *Context) {
+		var user validationUser
+		_ = c.BindAndValidate(&user)
+	})
+
+	tests := []struct {
+		method, path, body string
+		code               int
+		problem            string
+	}{
+		{http.MethodGet, "/missing", "", http.StatusNotFound,
+			`{"type":"about:blank","title":"Not Found","status":404,"instance":"/missing"}`},
+		{http.MethodDelete, "/panic", "", http.StatusMethodNotAllowed,
+			`{"type":"about:blank","title":"Method Not Allowed","status":405,"instance":"/panic"}`},
+		{http.MethodGet, "/panic", "", http.StatusInternalServerError,
+			`{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"internal server error","instance":"/panic","code":50000}`},
+		{http.MethodGet, "/fail", "", http.StatusForbidden,
+			`{"type":"about:blank","title":"Forbidden","status":403,"detail":"forbidden","instance":"/fail","code":40300}`},
+		{http.MethodPost, "/bind", "{", http.StatusBadRequest,
+			`{"type":"about:blank","title":"Bad Request","status":400,"detail":"unexpected EOF","instance":"/bind"}`},
+		{http.MethodPost, "/validate", `{"address":{"city":"x"}}`, http.StatusBadRequest,
+			`{"type":"about:blank","title":"Bad Request","status":400,"detail":"validation failed","instance":"/validate",
+			"errors":[{"field":"name","rule":"required","message":"name is a required field"}]}`},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", MIMEJSON)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.code, w.Code, tt.path)
+		assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"), tt.path)
+		assert.JSONEq(t, tt.problem, w.Body.String(), tt.path)
+	}
+}
@@ -0,0 +1,200 @@
+package gin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parsable QueryAs、ParamAs、FormAs、HeaderAs 支持的类型
+type Parsable interface {
+	int | int8 | int16 | int32 | int64 |
+		uint | uint8 | uint16 | uint32 | uint64 |
+		float32 | float64 | bool | string | time.Duration | time.Time |
+		[]string | []int | []int64 | []uint | []uint64 | []float64 | []bool | []time.Duration
+}
+
+// ParseError 请求中的值无法转换为目标类型时返回的错误
+type ParseError struct {
+	Source string // 值的来源：query、param、form、header
+	Key    string
+	Value  string
+	Type   string // 目标类型
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("gin: %s '%s' value '%s' can not be parsed as %s: %v", e.Source, e.Key, e.Value, e.Type, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// QueryAs 获取请求地址中的参数并转换为 T 类型，参数不存在时返回 def
+// 切片类型使用参数的所有值，每个值还会按逗号拆分，例如 ?id=1,2&id=3 => [1 2 3]
+// time.Time 按 layouts 依次尝试解析，没有指定时使用 time.RFC3339
+func QueryAs[T Parsable](ctx *Context, key string, def T, layouts ...string) (T, error) {
+	ctx.initQueryCache()
+	return parseValues("query", key, ctx.queryCache[key], def, layouts)
+}
+
+// ParamAs 获取路由参数并转换为 T 类型，参数不存在时返回 def
+func ParamAs[T Parsable](ctx *Context, key string, def T, layouts ...string) (T, error) {
+	val, ok := ctx.Params.Get(key)
+	if !ok {
+		return def, nil
+	}
+	return parseValues("param", key, []string{val}, def, layouts)
+}
+
+// FormAs 获取表单中的参数并转换为 T 类型，参数不存在时返回 def
+func FormAs[T Parsable](ctx *Context, key string, def T, layouts ...string) (T, error) {
+	return parseValues("form", key, ctx.FormAll()[key], def, layouts)
+}
+
+// HeaderAs 获取请求头并转换为 T 类型，请求头不存在时返回 def
+func HeaderAs[T Parsable](ctx *Context, key string, def T, layouts ...string) (T, error) {
+	return parseValues("header", key, ctx.Request.Header.Values(key), def, layouts)
+}
+
+// parseValues 把 values 转换为 T 类型，非切片类型只使用第一个值，转换失败时返回 def 和 *ParseError
+func parseValues[T Parsable](source, key string, values []string, def T, layouts []string) (T, error) {
+	if len(values) == 0 {
+		return def, nil
+	}
+	var out T
+	var raw string
+	var err error
+	switch p := any(&out).(type) {
+	case *string:
+		*p = values[0]
+	case *int:
+		*p, raw, err = parseSigned[int](values[0], strconv.IntSize)
+	case *int8:
+		*p, raw, err = parseSigned[int8](values[0], 8)
+	case *int16:
+		*p, raw, err = parseSigned[int16](values[0], 16)
+	case *int32:
+		*p, raw, err = parseSigned[int32](values[0], 32)
+	case *int64:
+		*p, raw, err = parseSigned[int64](values[0], 64)
+	case *uint:
+		*p, raw, err = parseUnsigned[uint](values[0], strconv.IntSize)
+	case *uint8:
+		*p, raw, err = parseUnsigned[uint8](values[0], 8)
+	case *uint16:
+		*p, raw, err = parseUnsigned[uint16](values[0], 16)
+	case *uint32:
+		*p, raw, err = parseUnsigned[uint32](values[0], 32)
+	case *uint64:
+		*p, raw, err = parseUnsigned[uint64](values[0], 64)
+	case *float32:
+		*p, raw, err = parseFloat[float32](values[0], 32)
+	case *float64:
+		*p, raw, err = parseFloat[float64](values[0], 64)
+	case *bool:
+		*p, raw, err = parseBool(values[0])
+	case *time.Duration:
+		*p, raw, err = parseDuration(values[0])
+	case *time.Time:
+		*p, raw, err = parseTime(values[0], layouts)
+	case *[]string:
+		*p = splitValues(values)
+	case *[]int:
+		*p, raw, err = parseSlice(values, func(s string) (int, string, error) { return parseSigned[int](s, strconv.IntSize) })
+	case *[]int64:
+		*p, raw, err = parseSlice(values, func(s string) (int64, string, error) { return parseSigned[int64](s, 64) })
+	case *[]uint:
+		*p, raw, err = parseSlice(values, func(s string) (uint, string, error) { return parseUnsigned[uint](s, strconv.IntSize) })
+	case *[]uint64:
+		*p, raw, err = parseSlice(values, func(s string) (uint64, string, error) { return parseUnsigned[uint64](s, 64) })
+	case *[]float64:
+		*p, raw, err = parseSlice(values, func(s string) (float64, string, error) { return parseFloat[float64](s, 64) })
+	case *[]bool:
+		*p, raw, err = parseSlice(values, parseBool)
+	case *[]time.Duration:
+		*p, raw, err = parseSlice(values, parseDuration)
+	}
+	if err != nil {
+		return def, &ParseError{Source: source, Key: key, Value: raw, Type: fmt.Sprintf("%T", out), Err: err}
+	}
+	return out, nil
+}
+
+// 以下解析函数额外返回被解析的原始值，用于生成 ParseError
+
+func parseSigned[N int | int8 | int16 | int32 | int64](s string, bitSize int) (N, string, error) {
+	v, err := strconv.ParseInt(strings.TrimSpace(s), 10, bitSize)
+	return N(v), s, numError(err)
+}
+
+func parseUnsigned[N uint | uint8 | uint16 | uint32 | uint64](s string, bitSize int) (N, string, error) {
+	v, err := strconv.ParseUint(strings.TrimSpace(s), 10, bitSize)
+	return N(v), s, numError(err)
+}
+
+func parseFloat[N float32 | float64](s string, bitSize int) (N, string, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), bitSize)
+	return N(v), s, numError(err)
+}
+
+func parseBool(s string) (bool, string, error) {
+	v, err := strconv.ParseBool(strings.TrimSpace(s))
+	return v, s, numError(err)
+}
+
+func parseDuration(s string) (time.Duration, string, error) {
+	v, err := time.ParseDuration(strings.TrimSpace(s))
+	return v, s, err
+}
+
+func parseTime(s string, layouts []string) (time.Time, string, error) {
+	if len(layouts) == 0 {
+		layouts = []string{time.RFC3339}
+	}
+	var err error
+	for _, layout := range layouts {
+		var t time.Time
+		if t, err = time.Parse(layout, strings.TrimSpace(s)); err == nil {
+			return t, s, nil
+		}
+	}
+	return time.Time{}, s, err
+}
+
+// parseSlice 按逗号拆分所有的值后逐个解析
+func parseSlice[E any](values []string, parse func(string) (E, string, error)) ([]E, string, error) {
+	parts := splitValues(values)
+	out := make([]E, 0, len(parts))
+	for _, part := range parts {
+		v, raw, err := parse(part)
+		if err != nil {
+			return nil, raw, err
+		}
+		out = append(out, v)
+	}
+	return out, "", nil
+}
+
+// splitValues 把每个值按逗号拆分，去掉首尾空白，忽略空值
+func splitValues(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
+}
+
+// numError strconv 的错误中已经包含了原始值，只保留原因，例如 strconv.ErrSyntax
+func numError(err error) error {
+	if ne, ok := err.(*strconv.NumError); ok {
+		return ne.Err
+	}
+	return err
+}
@@ -0,0 +1,100 @@
+package gin
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypedAccessors(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost,
+		"/?page=2&big=300&ids=1,2&ids=3&ratio=0.5&on=true&ttl=1m30s&at=2023-05-01&bad=x",
+		strings.NewReader("name=foo&age=18"))
+	c.Request.Header.Set("Content-Type", MIMEPOSTForm)
+	c.Request.Header.Set("X-Retry", "3")
+	c.Request.Header.Add("X-Tags", "a, b")
+	c.Request.Header.Add("X-Tags", "c")
+	c.Params = Params{{Key: "id", Value: "42"}}
+
+	page, err := QueryAs(c, "page", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, page)
+
+	missing, err := QueryAs(c, "missing", uint(7))
+	assert.NoError(t, err)
+	assert.Equal(t, uint(7), missing)
+
+	ids, err := QueryAs[[]int64](c, "ids", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3}, ids)
+
+	ratio, err := QueryAs(c, "ratio", float32(0))
+	assert.NoError(t, err)
+	assert.Equal(t, float32(0.5), ratio)
+
+	on, err := QueryAs(c, "on", false)
+	assert.NoError(t, err)
+	assert.True(t, on)
+
+	ttl, err := QueryAs(c, "ttl", time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, 90*time.Second, ttl)
+
+	at, err := QueryAs(c, "at", time.Time{}, time.RFC3339, "2006-01-02")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC), at)
+
+	id, err := ParamAs(c, "id", uint64(0))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(42), id)
+
+	age, err := FormAs(c, "age", int8(0))
+	assert.NoError(t, err)
+	assert.Equal(t, int8(18), age)
+
+	name, err := FormAs(c, "name", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", name)
+
+	retry, err := HeaderAs(c, "X-Retry", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, retry)
+
+	tags, err := HeaderAs[[]string](c, "X-Tags", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, tags)
+}
+
+func TestTypedAccessorsParseError(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/?big=300&bad=x&ids=1,y", nil)
+
+	big, err := QueryAs(c, "big", uint8(1))
+	assert.Equal(t, uint8(1), big)
+	var pe *ParseError
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, "query", pe.Source)
+	assert.Equal(t, "big", pe.Key)
+	assert.Equal(t, "300", pe.Value)
+	assert.Equal(t, "uint8", pe.Type)
+	assert.ErrorIs(t, err, strconv.ErrRange)
+
+	_, err = QueryAs(c, "bad", false)
+	assert.ErrorIs(t, err, strconv.ErrSyntax)
+
+	ids, err := QueryAs(c, "ids", []int{9})
+	assert.Equal(t, []int{9}, ids)
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, "y", pe.Value)
+	assert.Equal(t, "[]int", pe.Type)
+
+	_, err = QueryAs(c, "bad", time.Time{})
+	assert.Error(t, err)
+}
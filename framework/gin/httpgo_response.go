@@ -0,0 +1,167 @@
+package gin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// CodeSuccess Success 响应中的业务码
+const CodeSuccess = 0
+
+// AppError 应用错误，包含业务码、HTTP 状态码和返回给调用方的信息
+// 通过 RegisterError 注册，使用 Wrap 附带原始错误，原始错误只记录在 Context.Errors 中，不会返回给调用方
+type AppError struct {
+	Code    int
+	Status  int
+	Message string
+	Err     error
+}
+
+func (e *AppError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("[%d] %s: %v", e.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("[%d] %s", e.Code, e.Message)
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// Is 业务码相同的 AppError 视为同一个错误，例如 errors.Is(err, ErrNotFound)
+func (e *AppError) Is(target error) bool {
+	t, ok := target.(*AppError)
+	return ok && t.Code == e.Code
+}
+
+// Wrap 返回附带了原始错误的副本
+func (e *AppError) Wrap(err error) *AppError {
+	cp := *e
+	cp.Err = err
+	return &cp
+}
+
+// WithMessage 返回修改了信息的副本
+func (e *AppError) WithMessage(format string, args ...interface{}) *AppError {
+	cp := *e
+	cp.Message = fmt.Sprintf(format, args...)
+	return &cp
+}
+
+// errorRegistry 注册的业务码
+var errorRegistry = struct {
+	sync.RWMutex
+	errors map[int]*AppError
+}{errors: make(map[int]*AppError)}
+
+// 内置的错误
+var (
+	ErrBadRequest   = RegisterError(40000, http.StatusBadRequest, "bad request")
+	ErrUnauthorized = RegisterError(40100, http.StatusUnauthorized, "unauthorized")
+	ErrForbidden    = RegisterError(40300, http.StatusForbidden, "forbidden")
+	ErrNotFound     = RegisterError(40400, http.StatusNotFound, "not found")
+	ErrInternal     = RegisterError(50000, http.StatusInternalServerError, "internal server error")
+)
+
+// RegisterError 注册业务码，业务码重复或者为 CodeSuccess 时 panic，一般在包级变量中注册：
+//
+//	var ErrUserNotFound = gin.RegisterError(40401, http.StatusNotFound, "user not found")
+//
+// 业务码为 HTTP 状态码 * 100 + 序号，为了避免和 gin 内置的错误冲突，序号按下面的规则分配：
+//
+//	00     gin 内置的错误，例如 ErrBadRequest 40000、ErrUploadConflict 40900、ErrTusVersion 41200
+//	01~89  应用自定义的错误
+//	90~99  gin 内置的错误，同一个状态码的 00 已经被使用时从 90 开始，例如 ErrPreconditionFailed 41290
+func RegisterError(code, status int, message string) *AppError {
+	if code == CodeSuccess {
+		panic(fmt.Sprintf("gin: error code %d is reserved for success", code))
+	}
+	errorRegistry.Lock()
+	defer errorRegistry.Unlock()
+	if _, ok := errorRegistry.errors[code]; ok {
+		panic(fmt.Sprintf("gin: error code %d is already registered", code))
+	}
+	e := &AppError{Code: code, Status: status, Message: message}
+	errorRegistry.errors[code] = e
+	return e
+}
+
+// LookupError 返回业务码对应的错误
+func LookupError(code int) (*AppError, bool) {
+	errorRegistry.RLock()
+	defer errorRegistry.RUnlock()
+	e, ok := errorRegistry.errors[code]
+	return e, ok
+}
+
+// RegisteredErrors 返回注册的所有错误，按业务码排序，可以用于生成错误码文档
+func RegisteredErrors() []*AppError {
+	errorRegistry.RLock()
+	defer errorRegistry.RUnlock()
+	list := make([]*AppError, 0, len(errorRegistry.errors))
+	for _, e := range errorRegistry.errors {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
+
+// AsAppError 通过 errors.As 找到 err 中的 AppError，找不到时返回包装了 err 的 ErrInternal
+func AsAppError(err error) *AppError {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return ErrInternal.Wrap(err)
+}
+
+// defaultEnvelope 默认的响应格式：{"code": 0, "msg": "ok", "data": ...}
+func defaultEnvelope(code int, message string, data interface{}) interface{} {
+	return H{"code": code, "msg": message, "data": data}
+}
+
+// Success 以 200 状态码输出包装了 data 的响应
+func (ctx *Context) Success(data interface{}) IResponse {
+	return ctx.ISetOkStatus().IJson(ctx.envelope(CodeSuccess, "ok", data))
+}
+
+// Fail 输出 err 对应的错误响应并终止后续的处理函数，err 会被记录到 Context.Errors 中
+// err 中包含 AppError 时使用它的业务码、状态码和信息，否则作为 ErrInternal 处理
+func (ctx *Context) Fail(err error) IResponse {
+	if err == nil {
+		err = ErrInternal
+	}
+	ctx.Error(err) //nolint: errcheck
+	ctx.Abort()
+	return ctx.renderAppError(AsAppError(err))
+}
+
+// renderAppError 输出 appErr，开启了 ProblemDetails 时输出 Problem，业务码在扩展字段 code 中
+func (ctx *Context) renderAppError(appErr *AppError) IResponse {
+	if ctx.problemDetails() {
+		ctx.AbortWithProblem(NewProblem(appErr.Status).WithDetail(appErr.Message).With("code", appErr.Code))
+		return ctx
+	}
+	return ctx.ISetStatus(appErr.Status).IJson(ctx.envelope(appErr.Code, appErr.Message, nil))
+}
+
+func (ctx *Context) envelope(code int, message string, data interface{}) interface{} {
+	if envelope := ctx.engine.ResponseEnvelope; envelope != nil {
+		return envelope(code, message, data)
+	}
+	return defaultEnvelope(code, message, data)
+}
+
+// ErrorResponder 中间件，后续的处理函数通过 c.Error 记录了错误但没有写入响应时，使用最后一个错误输出 Fail 的响应
+func ErrorResponder() HandlerFunc {
+	return func(c *Context) {
+		c.Next()
+		if c.Writer.Written() || len(c.Errors) == 0 {
+			return
+		}
+		c.renderAppError(AsAppError(c.Errors.Last().Err))
+	}
+}
@@ -0,0 +1,114 @@
+package gin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errTestUserNotFound = RegisterError(40401, http.StatusNotFound, "user not found")
+
+func TestSuccessAndFail(t *testing.T) {
+	router := New()
+	router.GET("/ok", func(c *Context) { c.Success(H{"id": 1}) })
+	router.GET("/fail", func(c *Context) {
+		c.Fail(fmt.Errorf("load user: %w", errTestUserNotFound.Wrap(errors.New("sql: no rows"))))
+	})
+	router.GET("/internal", func(c *Context) { c.Fail(errors.New("boom")) })
+
+	w := PerformRequest(router, http.MethodGet, "/ok")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"code":0,"msg":"ok","data":{"id":1}}`, w.Body.String())
+
+	w = PerformRequest(router, http.MethodGet, "/fail")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"code":40401,"msg":"user not found","data":null}`, w.Body.String())
+
+	w = PerformRequest(router, http.MethodGet, "/internal")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.JSONEq(t, `{"code":50000,"msg":"internal server error","data":null}`, w.Body.String())
+
+	router.ResponseEnvelope = func(code int, message string, data interface{}) interface{} {
+		return H{"errno": code, "errmsg": message, "result": data}
+	}
+	w = PerformRequest(router, http.MethodGet, "/ok")
+	assert.JSONEq(t, `{"errno":0,"errmsg":"ok","result":{"id":1}}`, w.Body.String())
+}
+
+func TestErrorResponderAndRecovery(t *testing.T) {
+	router := New()
+	router.Use(RecoveryWithWriter(nil), ErrorResponder())
+	router.GET("/error", func(c *Context) {
+		c.Error(ErrForbidden) //nolint: errcheck
+	})
+	router.GET("/panic", func(c *Context) { panic("oops") })
+
+	w := PerformRequest(router, http.MethodGet, "/error")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.JSONEq(t, `{"code":40300,"msg":"forbidden","data":null}`, w.Body.String())
+
+	w = PerformRequest(router, http.MethodGet, "/panic")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.JSONEq(t, `{"code":50000,"msg":"internal server error","data":null}`, w.Body.String())
+}
+
+func TestErrorRegistry(t *testing.T) {
+	e, ok := LookupError(40401)
+	assert.True(t, ok)
+	assert.Same(t, errTestUserNotFound, e)
+	assert.True(t, errors.Is(errTestUserNotFound.Wrap(errors.New("x")), errTestUserNotFound))
+	assert.Equal(t, "user 7 not found", errTestUserNotFound.WithMessage("user %d not found", 7).Message)
+
+	assert.Panics(t, func() { RegisterError(40401, http.StatusNotFound, "dup") })
+	assert.Panics(t, func() { RegisterError(CodeSuccess, http.StatusOK, "ok") })
+
+	codes := RegisteredErrors()
+	for i := 1; i < len(codes); i++ {
+		assert.Less(t, codes[i-1].Code, codes[i].Code)
+	}
+}
+
+func TestIResponseRender(t *testing.T) {
+	router := New()
+	router.GET("/json", func(c *Context) { c.ISetStatus(http.StatusCreated).IJson(H{"html": "<b>"}) })
+	router.GET("/stream", func(c *Context) { c.IJsonStream([]int{1, 2}) })
+	router.GET("/jsonp", func(c *Context) { c.IJsonp(H{"a": 1}) })
+	router.GET("/xml", func(c *Context) { c.IXml(H{"a": "b"}) })
+	router.GET("/text", func(c *Context) { c.IText("hello %s", "world") })
+	router.GET("/bad", func(c *Context) { c.IJson(make(chan int)) })
+	router.GET("/html", func(c *Context) { c.IHtml("./testdata/template/raw.tmpl", H{"now": time.Date(2017, 07, 01, 0, 0, 0, 0, time.UTC)}) })
+
+	w := PerformRequest(router, http.MethodGet, "/json")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `{"html":"\u003cb\u003e"}`, w.Body.String())
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+
+	w = PerformRequest(router, http.MethodGet, "/stream")
+	assert.Equal(t, "[1,2]\n", w.Body.String())
+
+	w = PerformRequest(router, http.MethodGet, "/jsonp?callback=x")
+	assert.Equal(t, `x({"a":1});`, w.Body.String())
+	assert.Equal(t, "application/javascript; charset=utf-8", w.Header().Get("Content-Type"))
+
+	w = PerformRequest(router, http.MethodGet, "/xml")
+	assert.Equal(t, "<map><a>b</a></map>", w.Body.String())
+	assert.Equal(t, "application/xml; charset=utf-8", w.Header().Get("Content-Type"))
+
+	w = PerformRequest(router, http.MethodGet, "/text")
+	assert.Equal(t, "hello world", w.Body.String())
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+
+	w = PerformRequest(router, http.MethodGet, "/bad")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	router.Delims("{[{", "}]}")
+	router.FuncMap["formatAsDate"] = func(t time.Time) string { return t.Format("2006/01/02") }
+	w = PerformRequest(router, http.MethodGet, "/html")
+	assert.Equal(t, "Date: 2017/07/01", w.Body.String())
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+}
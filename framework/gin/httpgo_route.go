@@ -0,0 +1,130 @@
+package gin
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// routeKey 一条路由的唯一标识：host 模式 + 请求方法 + 完整路径
+type routeKey struct {
+	host   string
+	method string
+	path   string
+}
+
+// namedRoute 命名路由的信息
+type namedRoute struct {
+	path   string
+	routes []routeKey
+}
+
+// Name 给最近一次注册的路由命名，Any/Match/Static 等一次注册多个方法的路由共用一个名字
+// 名字全局唯一，重复使用名字或者重复命名同一个路由会 panic，例如：r.GET("/demo/:id", handler).Name("demo.show")
+func (group *RouterGroup) Name(name string) IRoutes {
+	group.engine.nameRoute(name)
+	return group.returnObj()
+}
+
+// handleAll 为多个方法注册同一个路由，并把它们整体记录为最近一次注册的路由
+func (group *RouterGroup) handleAll(methods []string, relativePath string, handlers HandlersChain) IRoutes {
+	absolutePath := group.calculateAbsolutePath(relativePath)
+	routes := make([]routeKey, 0, len(methods))
+	for _, method := range methods {
+		group.handle(method, relativePath, handlers)
+		routes = append(routes, routeKey{host: group.hostPattern(), method: method, path: absolutePath})
+	}
+	group.engine.setLastRoutes(routes)
+	return group.returnObj()
+}
+
+// setLastRoutes 记录最近一次注册的路由，和 Name、Doc 一样需要持有 routesMu，避免和运行时读取路由信息竞争
+func (engine *Engine) setLastRoutes(routes []routeKey) {
+	engine.routesMu.Lock()
+	engine.lastRoutes = routes
+	engine.routesMu.Unlock()
+}
+
+// nameRoute 记录名字和最近一次注册的路由的对应关系
+func (engine *Engine) nameRoute(name string) {
+	engine.routesMu.Lock()
+	defer engine.routesMu.Unlock()
+	assert1(name != "", "route name can not be empty")
+	assert1(len(engine.lastRoutes) > 0, "route name '"+name+"' must follow a route registration")
+	if _, ok := engine.namedRoutes[name]; ok {
+		panic("route name '" + name + "' is already registered")
+	}
+	routes := engine.lastRoutes
+	// 一次注册只能命名一次
+	if named, ok := engine.routeNames[routes[0]]; ok {
+		panic("route is already named '" + named + "', can not be named '" + name + "'")
+	}
+	if engine.namedRoutes == nil {
+		engine.namedRoutes = make(map[string]namedRoute)
+		engine.routeNames = make(map[routeKey]string)
+	}
+	// {id:int} 形式的参数按 :id 生成 URL
+	path, _ := parseParamConstraints(routes[0].path)
+	engine.namedRoutes[name] = namedRoute{path: path, routes: routes}
+	for _, route := range routes {
+		engine.routeNames[route] = name
+	}
+}
+
+// URL 根据路由名字生成 URL，params 按顺序填充路径中的 :param 和 *catchAll
+// :param 的值会做路径转义，*catchAll 的值按 / 分段转义，例如：
+// r.GET("/book/:id/*path", handler).Name("book")
+// engine.URL("book", 12, "a b/c.txt") => /book/12/a%20b/c.txt
+func (engine *Engine) URL(name string, params ...interface{}) (string, error) {
+	// RemoveRoute 会在运行时删除名字
+	engine.routesMu.Lock()
+	route, ok := engine.namedRoutes[name]
+	engine.routesMu.Unlock()
+	if !ok {
+		return "", fmt.Errorf("gin: route name '%s' not found", name)
+	}
+
+	path := route.path
+	var sb strings.Builder
+	sb.Grow(len(path))
+	i := 0
+	for len(path) > 0 {
+		start := strings.IndexAny(path, ":*")
+		if start < 0 {
+			sb.WriteString(path)
+			break
+		}
+		sb.WriteString(path[:start])
+		wildcard := path[start]
+		end := strings.IndexByte(path[start:], '/')
+		if end < 0 {
+			end = len(path)
+		} else {
+			end += start
+		}
+		if i >= len(params) {
+			return "", fmt.Errorf("gin: route '%s' missing value for %s", name, path[start:end])
+		}
+		value := fmt.Sprint(params[i])
+		i++
+		if wildcard == ':' {
+			sb.WriteString(url.PathEscape(value))
+		} else {
+			segments := strings.Split(strings.TrimPrefix(value, "/"), "/")
+			for j, segment := range segments {
+				segments[j] = url.PathEscape(segment)
+			}
+			sb.WriteString(strings.Join(segments, "/"))
+		}
+		path = path[end:]
+	}
+	if i != len(params) {
+		return "", fmt.Errorf("gin: route '%s' expects %d params, got %d", name, i, len(params))
+	}
+	return sb.String(), nil
+}
+
+// routeName 返回路由的名字，未命名返回空字符串，需要持有 routesMu
+func (engine *Engine) routeName(host, method, path string) string {
+	return engine.routeNames[routeKey{host: host, method: method, path: path}]
+}
@@ -0,0 +1,102 @@
+package gin
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteName(t *testing.T) {
+	router := New()
+	handler := func(c *Context) {}
+	router.GET("/", handler).Name("home")
+	v1 := router.Group("/v1")
+	v1.GET("/book/:id", handler).Name("book.show")
+	v1.POST("/book", handler)
+	v1.Match([]string{http.MethodPut, http.MethodPatch}, "/book/:id", handler).Name("book.update")
+	router.Static("/assets", ".").Name("assets")
+
+	names := map[string]string{}
+	for _, route := range router.Routes() {
+		names[route.Method+" "+route.Path] = route.Name
+	}
+	assert.Equal(t, "home", names["GET /"])
+	assert.Equal(t, "book.show", names["GET /v1/book/:id"])
+	assert.Equal(t, "", names["POST /v1/book"])
+	assert.Equal(t, "book.update", names["PUT /v1/book/:id"])
+	assert.Equal(t, "book.update", names["PATCH /v1/book/:id"])
+	assert.Equal(t, "assets", names["GET /assets/*filepath"])
+	assert.Equal(t, "assets", names["HEAD /assets/*filepath"])
+}
+
+func TestRouteNamePanics(t *testing.T) {
+	router := New()
+	handler := func(c *Context) {}
+	assert.Panics(t, func() { router.Name("empty") })
+
+	router.GET("/a", handler).Name("a")
+	assert.Panics(t, func() { router.Name("again") })
+	router.GET("/b", handler)
+	assert.Panics(t, func() { router.Name("a") })
+	assert.Panics(t, func() { router.Name("") })
+}
+
+func TestRouteNameThenDoc(t *testing.T) {
+	router := New()
+	handler := func(c *Context) {}
+	assert.NotPanics(t, func() {
+		router.GET("/a", handler).Name("a").Doc(RouteDoc{Summary: "a"})
+		router.GET("/b", handler).Doc(RouteDoc{Summary: "b"}).Name("b")
+	})
+	for _, route := range router.Routes() {
+		if assert.NotNil(t, route.Doc, route.Path) {
+			assert.Equal(t, route.Name, route.Doc.Summary)
+		}
+	}
+}
+
+func TestEngineURL(t *testing.T) {
+	router := New()
+	handler := func(c *Context) {}
+	router.GET("/demo/demo", handler).Name("demo.list")
+	router.GET("/user/:name/book/:id", handler).Name("user.book")
+	router.GET("/files/*path", handler).Name("files")
+
+	url, err := router.URL("demo.list")
+	assert.NoError(t, err)
+	assert.Equal(t, "/demo/demo", url)
+
+	url, err = router.URL("user.book", "a b/c", 12)
+	assert.NoError(t, err)
+	assert.Equal(t, "/user/a%20b%2Fc/book/12", url)
+
+	url, err = router.URL("files", "/docs/a b.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "/files/docs/a%20b.txt", url)
+
+	_, err = router.URL("missing")
+	assert.Error(t, err)
+	_, err = router.URL("user.book", "gin")
+	assert.Error(t, err)
+	_, err = router.URL("demo.list", 1)
+	assert.Error(t, err)
+}
+
+func TestEngineURLFuncMap(t *testing.T) {
+	router := New()
+	router.GET("/book/:id", func(c *Context) {}).Name("book")
+
+	var buf bytes.Buffer
+	tmpl := template.Must(template.New("").Funcs(router.FuncMap).Parse(`{{ url "book" 7 }}`))
+	assert.NoError(t, tmpl.Execute(&buf, nil))
+	assert.Equal(t, "/book/7", buf.String())
+
+	router.SetFuncMap(template.FuncMap{})
+	assert.Contains(t, router.FuncMap, "url")
+	custom := func() string { return "custom" }
+	router.SetFuncMap(template.FuncMap{"url": custom})
+	assert.Equal(t, "custom", router.FuncMap["url"].(func() string)())
+}
@@ -0,0 +1,259 @@
+package gin
+
+import (
+	"errors"
+	"fmt"
+)
+
+// routeTable 运行时修改路由后发布的路由表，发布后不再修改，每次修改都会复制受影响的部分生成新的路由表
+type routeTable struct {
+	trees       methodTrees
+	hosts       []*hostRouter
+	maxParams   uint16
+	maxSections uint16
+}
+
+// treeRoute 路由树中的一条路由
+type treeRoute struct {
+	path     string
+	handlers HandlersChain
+}
+
+// AddRoute 在服务运行时添加路由，并发安全，handlers 之前会加上 engine 的全局中间件
+// 受影响的路由树会复制一份后重建，再整体替换，处理中的请求不受影响
+func (engine *Engine) AddRoute(method, path string, handlers ...HandlerFunc) error {
+	if err := checkRuntimeRoute(method, path); err != nil {
+		return err
+	}
+	if len(handlers) == 0 {
+		return errors.New("gin: there must be at least one handler")
+	}
+	chain := engine.combineHandlers(handlers)
+	debugPrintRoute(method, path, chain)
+	return engine.addRuntimeRoute("", method, path, chain)
+}
+
+// RemoveRoute 在服务运行时删除路由，并发安全，path 为注册时的完整路径
+// 路由的名字和文档信息一起删除，之后 URL 不再能生成这个名字的 URL
+func (engine *Engine) RemoveRoute(method, path string) error {
+	return engine.RemoveHostRoute("", method, path)
+}
+
+// RemoveHostRoute 在服务运行时删除 Host 注册的路由，host 为注册时的 host 模式，为空时删除默认路由树中的路由
+func (engine *Engine) RemoveHostRoute(host, method, path string) error {
+	if err := checkRuntimeRoute(method, path); err != nil {
+		return err
+	}
+	return engine.updateRoutes(host, method, func(routes []treeRoute) ([]treeRoute, error) {
+		for i, route := range routes {
+			if route.path == path {
+				engine.forgetRoute(routeKey{host: host, method: method, path: path})
+				return append(routes[:i:i], routes[i+1:]...), nil
+			}
+		}
+		return nil, routeNotFound(host, method, path)
+	})
+}
+
+// forgetRoute 删除路由的名字和文档信息，需要持有 routesMu
+// 一个名字对应多个方法的路由时只移除这一条，所有路由都删除后名字才失效
+func (engine *Engine) forgetRoute(key routeKey) {
+	delete(engine.routeDocs, key)
+	name, ok := engine.routeNames[key]
+	if !ok {
+		return
+	}
+	delete(engine.routeNames, key)
+	named := engine.namedRoutes[name]
+	routes := make([]routeKey, 0, len(named.routes))
+	for _, route := range named.routes {
+		if route != key {
+			routes = append(routes, route)
+		}
+	}
+	if len(routes) == 0 {
+		delete(engine.namedRoutes, name)
+		return
+	}
+	named.routes = routes
+	engine.namedRoutes[name] = named
+}
+
+// ReplaceHandlers 在服务运行时替换路由的处理函数，并发安全，handlers 之前会加上 engine 的全局中间件
+func (engine *Engine) ReplaceHandlers(method, path string, handlers ...HandlerFunc) error {
+	return engine.ReplaceHostHandlers("", method, path, handlers...)
+}
+
+// ReplaceHostHandlers 在服务运行时替换 Host 注册的路由的处理函数，host 为空时替换默认路由树中的路由
+// handlers 之前只会加上 engine 的全局中间件，Host 路由组的中间件需要包含在 handlers 中
+func (engine *Engine) ReplaceHostHandlers(host, method, path string, handlers ...HandlerFunc) error {
+	if err := checkRuntimeRoute(method, path); err != nil {
+		return err
+	}
+	if len(handlers) == 0 {
+		return errors.New("gin: there must be at least one handler")
+	}
+	chain := engine.combineHandlers(handlers)
+	return engine.updateRoutes(host, method, func(routes []treeRoute) ([]treeRoute, error) {
+		for i, route := range routes {
+			if route.path == path {
+				replaced := append([]treeRoute(nil), routes...)
+				replaced[i].handlers = chain
+				return replaced, nil
+			}
+		}
+		return nil, routeNotFound(host, method, path)
+	})
+}
+
+func routeNotFound(host, method, path string) error {
+	if host == "" {
+		return fmt.Errorf("gin: route %s %s not found", method, path)
+	}
+	return fmt.Errorf("gin: route %s %s%s not found", method, host, path)
+}
+
+func checkRuntimeRoute(method, path string) error {
+	if !regEnLetter.MatchString(method) {
+		return fmt.Errorf("gin: http method %s is not valid", method)
+	}
+	if len(path) == 0 || path[0] != '/' {
+		return fmt.Errorf("gin: path %s must begin with '/'", path)
+	}
+	return nil
+}
+
+// addRuntimeRoute 向 host 模式的路由树中添加路由，hostPattern 为空时添加到默认的路由树
+func (engine *Engine) addRuntimeRoute(hostPattern, method, path string, handlers HandlersChain) error {
+	return engine.updateRoutes(hostPattern, method, func(routes []treeRoute) ([]treeRoute, error) {
+		return append(routes[:len(routes):len(routes)], treeRoute{path: path, handlers: handlers}), nil
+	})
+}
+
+// updateRoutes 复制当前的路由表，用 update 修改 host 模式下 method 的路由后重建这一棵路由树，再原子地替换路由表
+// 其他的路由树和 host 直接复用，重建失败时路由表保持不变
+func (engine *Engine) updateRoutes(hostPattern, method string, update func([]treeRoute) ([]treeRoute, error)) (err error) {
+	engine.routesMu.Lock()
+	defer engine.routesMu.Unlock()
+	defer func() {
+		// 路由冲突等错误在 addRoute 中以 panic 的方式抛出
+		if r := recover(); r != nil {
+			err = fmt.Errorf("gin: %v", r)
+		}
+	}()
+
+	old := engine.table.Load()
+	if old == nil {
+		old = &routeTable{
+			trees:       engine.trees,
+			hosts:       engine.hosts,
+			maxParams:   engine.maxParams,
+			maxSections: engine.maxSections,
+		}
+	}
+	table := &routeTable{
+		trees:       old.trees,
+		hosts:       append([]*hostRouter(nil), old.hosts...),
+		maxParams:   old.maxParams,
+		maxSections: old.maxSections,
+	}
+
+	var host *hostRouter
+	trees := &table.trees
+	if hostPattern != "" {
+		host = findHostRouter(table.hosts, hostPattern)
+		if host == nil {
+			host = newHostRouter(hostPattern)
+			table.hosts = insertHostRouter(table.hosts, host)
+		}
+		copied := *host
+		host = &copied
+		for i := range table.hosts {
+			if table.hosts[i].pattern == hostPattern {
+				table.hosts[i] = host
+			}
+		}
+		trees = &host.trees
+	}
+
+	routes, err := update(collectRoutes(trees.get(method), nil))
+	if err != nil {
+		return err
+	}
+	var root *node
+	if len(routes) > 0 {
+		root = &node{fullPath: "/"}
+		for _, route := range routes {
+			root.addRoute(route.path, route.handlers)
+			if paramsCount := countParams(route.path) + hostParamsCount(host); paramsCount > table.maxParams {
+				table.maxParams = paramsCount
+			}
+			if sectionsCount := countSections(route.path); sectionsCount > table.maxSections {
+				table.maxSections = sectionsCount
+			}
+		}
+	}
+	*trees = replaceTree(*trees, method, root)
+
+	engine.table.Store(table)
+	return nil
+}
+
+// routing 返回当前用于匹配请求的路由树和 host 路由，运行时修改过路由后使用发布的路由表
+func (engine *Engine) routing() (methodTrees, []*hostRouter) {
+	if table := engine.table.Load(); table != nil {
+		return table.trees, table.hosts
+	}
+	return engine.trees, engine.hosts
+}
+
+// ensureParamsCap 运行时添加的路由可能超出 Context 预分配的参数和 skippedNodes 容量，需要重新分配
+func (engine *Engine) ensureParamsCap(c *Context) {
+	table := engine.table.Load()
+	if table == nil {
+		return
+	}
+	if cap(*c.params) < int(table.maxParams) {
+		params := make(Params, 0, table.maxParams)
+		c.params = &params
+	}
+	if cap(*c.skippedNodes) < int(table.maxSections) {
+		skippedNodes := make([]skippedNode, 0, table.maxSections)
+		c.skippedNodes = &skippedNodes
+	}
+}
+
+// collectRoutes 收集 n 下所有注册了 handlers 的路由
+func collectRoutes(n *node, routes []treeRoute) []treeRoute {
+	if n == nil {
+		return routes
+	}
+	if n.handlers != nil {
+		routes = append(routes, treeRoute{path: n.fullPath, handlers: n.handlers})
+	}
+	for _, child := range n.children {
+		routes = collectRoutes(child, routes)
+	}
+	return routes
+}
+
+// replaceTree 返回替换了 method 路由树的新切片，root 为 nil 时删除该路由树，不修改原切片
+func replaceTree(trees methodTrees, method string, root *node) methodTrees {
+	replaced := make(methodTrees, 0, len(trees)+1)
+	for _, tree := range trees {
+		if tree.method != method {
+			replaced = append(replaced, tree)
+		}
+	}
+	if root != nil {
+		replaced = append(replaced, methodTree{method: method, root: root})
+	}
+	return replaced
+}
+
+func hostParamsCount(host *hostRouter) uint16 {
+	if host == nil {
+		return 0
+	}
+	return host.params
+}
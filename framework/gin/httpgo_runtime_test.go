@@ -0,0 +1,180 @@
+package gin
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuntimeRoutes(t *testing.T) {
+	router := New()
+	router.GET("/static", func(c *Context) { c.String(http.StatusOK, "static") })
+
+	assert.NoError(t, router.AddRoute(http.MethodGet, "/plugin/:a/:b/:c/:d", func(c *Context) {
+		c.String(http.StatusOK, c.Param("a")+c.Param("d"))
+	}))
+	assert.NoError(t, router.AddRoute(http.MethodPost, "/plugin", func(c *Context) { c.String(http.StatusOK, "post") }))
+
+	w := PerformRequest(router, http.MethodGet, "/plugin/1/2/3/4")
+	assert.Equal(t, "14", w.Body.String())
+	w = PerformRequest(router, http.MethodGet, "/static")
+	assert.Equal(t, "static", w.Body.String())
+
+	// 冲突的路由返回错误，路由表保持不变
+	assert.Error(t, router.AddRoute(http.MethodGet, "/plugin/:x/:b/:c/:d", func(c *Context) {}))
+	assert.Error(t, router.AddRoute(http.MethodGet, "/static", func(c *Context) {}))
+	assert.Error(t, router.AddRoute("get", "/x", func(c *Context) {}))
+	assert.Error(t, router.AddRoute(http.MethodGet, "x", func(c *Context) {}))
+	assert.Error(t, router.AddRoute(http.MethodGet, "/x"))
+	w = PerformRequest(router, http.MethodGet, "/plugin/1/2/3/4")
+	assert.Equal(t, "14", w.Body.String())
+
+	assert.NoError(t, router.ReplaceHandlers(http.MethodGet, "/static", func(c *Context) { c.String(http.StatusOK, "replaced") }))
+	w = PerformRequest(router, http.MethodGet, "/static")
+	assert.Equal(t, "replaced", w.Body.String())
+	assert.Error(t, router.ReplaceHandlers(http.MethodGet, "/missing", func(c *Context) {}))
+
+	assert.NoError(t, router.RemoveRoute(http.MethodPost, "/plugin"))
+	w = PerformRequest(router, http.MethodPost, "/plugin")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Error(t, router.RemoveRoute(http.MethodPost, "/plugin"))
+
+	// 发布路由表之后，通过路由组注册的路由也使用复制的方式添加
+	router.Group("/group").GET("/:id", func(c *Context) { c.String(http.StatusOK, c.Param("id")) })
+	router.Host("{tenant}.example.com").GET("/", func(c *Context) { c.String(http.StatusOK, c.Param("tenant")) })
+	w = PerformRequest(router, http.MethodGet, "/group/7")
+	assert.Equal(t, "7", w.Body.String())
+	w = PerformRequest(router, http.MethodGet, "http://acme.example.com/")
+	assert.Equal(t, "acme", w.Body.String())
+
+	paths := map[string]bool{}
+	for _, route := range router.Routes() {
+		paths[route.Method+" "+route.Path] = true
+	}
+	assert.Equal(t, map[string]bool{
+		"GET /static":             true,
+		"GET /plugin/:a/:b/:c/:d": true,
+		"GET /group/:id":          true,
+		"GET /":                   true,
+	}, paths)
+}
+
+func TestRemoveRouteForgetsName(t *testing.T) {
+	router := New()
+	handler := func(c *Context) {}
+	router.GET("/book/:id", handler).Name("book.show").Doc(RouteDoc{Summary: "show"})
+	router.Match([]string{http.MethodPut, http.MethodPatch}, "/book/:id", handler).Name("book.update")
+
+	assert.NoError(t, router.RemoveRoute(http.MethodGet, "/book/:id"))
+	_, err := router.URL("book.show", 1)
+	assert.Error(t, err)
+	assert.Empty(t, router.routeDoc("", http.MethodGet, "/book/:id"))
+
+	// 同一个名字的其他路由还在时名字仍然有效
+	assert.NoError(t, router.RemoveRoute(http.MethodPut, "/book/:id"))
+	url, err := router.URL("book.update", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "/book/1", url)
+	assert.NoError(t, router.RemoveRoute(http.MethodPatch, "/book/:id"))
+	_, err = router.URL("book.update", 1)
+	assert.Error(t, err)
+
+	// 删除后可以重新注册并使用同一个名字
+	router.GET("/book/:id", handler).Name("book.show")
+	url, err = router.URL("book.show", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "/book/2", url)
+}
+
+func TestRuntimeRoutesConcurrent(t *testing.T) {
+	router := New()
+	router.GET("/ping", func(c *Context) { c.String(http.StatusOK, "pong") })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				w := PerformRequest(router, http.MethodGet, "/ping")
+				assert.Equal(t, "pong", w.Body.String())
+			}
+		}()
+	}
+	for i := 0; i < 50; i++ {
+		path := "/plugin/" + strconv.Itoa(i)
+		assert.NoError(t, router.AddRoute(http.MethodGet, path, func(c *Context) {}))
+		if i%2 == 0 {
+			assert.NoError(t, router.RemoveRoute(http.MethodGet, path))
+		}
+	}
+	wg.Wait()
+	assert.Len(t, router.Routes(), 26)
+}
+
+func TestRuntimeNameConcurrent(t *testing.T) {
+	router := New()
+	router.GET("/ping", func(c *Context) {}).Name("ping")
+	assert.NoError(t, router.AddRoute(http.MethodGet, "/plugin", func(c *Context) {}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				url, err := router.URL("ping")
+				assert.NoError(t, err)
+				assert.Equal(t, "/ping", url)
+				router.Routes()
+			}
+		}()
+	}
+	// 发布路由表之后继续注册并命名路由，和 URL、Routes 并发
+	for i := 0; i < 50; i++ {
+		path := "/book/" + strconv.Itoa(i) + "/:id"
+		router.GET(path, func(c *Context) {}).Name("book" + strconv.Itoa(i)).Doc(RouteDoc{Summary: path})
+	}
+	wg.Wait()
+
+	url, err := router.URL("book7", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "/book/7/3", url)
+}
+
+func TestRuntimeHostRoutes(t *testing.T) {
+	router := New()
+	router.GET("/profile", func(c *Context) { c.String(http.StatusOK, "default") }).Name("profile")
+	tenant := router.Host("{tenant}.example.com")
+	tenant.GET("/profile", func(c *Context) { c.String(http.StatusOK, c.Param("tenant")) }).Name("tenant.profile")
+
+	assert.NoError(t, router.ReplaceHostHandlers("{tenant}.example.com", http.MethodGet, "/profile", func(c *Context) {
+		c.String(http.StatusOK, "replaced "+c.Param("tenant"))
+	}))
+	w := PerformRequest(router, http.MethodGet, "http://acme.example.com/profile")
+	assert.Equal(t, "replaced acme", w.Body.String())
+	w = PerformRequest(router, http.MethodGet, "/profile")
+	assert.Equal(t, "default", w.Body.String())
+	assert.Error(t, router.ReplaceHostHandlers("other.example.com", http.MethodGet, "/profile", func(c *Context) {}))
+
+	assert.NoError(t, router.RemoveHostRoute("{tenant}.example.com", http.MethodGet, "/profile"))
+	w = PerformRequest(router, http.MethodGet, "http://acme.example.com/profile")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	_, err := router.URL("tenant.profile")
+	assert.Error(t, err)
+	assert.Error(t, router.RemoveHostRoute("{tenant}.example.com", http.MethodGet, "/profile"))
+	assert.Error(t, router.RemoveHostRoute("other.example.com", http.MethodGet, "/profile"))
+
+	// 默认路由树中的同名路径不受影响
+	w = PerformRequest(router, http.MethodGet, "/profile")
+	assert.Equal(t, "default", w.Body.String())
+	url, err := router.URL("profile")
+	assert.NoError(t, err)
+	assert.Equal(t, "/profile", url)
+	for _, route := range router.Routes() {
+		assert.Empty(t, route.Host)
+	}
+}
@@ -0,0 +1,221 @@
+package gin
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-contrib/sse"
+)
+
+// ErrSSEBrokerClosed SSEBroker 已经关闭
+var ErrSSEBrokerClosed = errors.New("gin: sse broker closed")
+
+// SSEBrokerOptions SSEBroker 的选项，为零值的字段使用默认值
+type SSEBrokerOptions struct {
+	// BufferSize 每个主题保留的最近事件数，客户端带着 Last-Event-ID 重连时从中补发，默认 100
+	BufferSize int
+	// KeepAlive 发送保活注释的间隔，避免连接被代理断开，默认 15 秒，小于 0 时不发送
+	KeepAlive time.Duration
+	// Retry 建议客户端断线后重连的间隔，为 0 时不发送
+	Retry time.Duration
+	// ClientBuffer 每个客户端待发送事件的队列长度，队列满时断开该客户端，由客户端重连后补发，默认 64
+	ClientBuffer int
+}
+
+// SSEBroker Server-Sent Events 的消息代理，按主题向订阅的客户端推送事件
+// 事件的 id 在整个 SSEBroker 中递增，客户端重连时根据 Last-Event-ID 请求头补发缓冲区中之后的事件
+type SSEBroker struct {
+	opts   SSEBrokerOptions
+	mu     sync.Mutex
+	lastID uint64
+	topics map[string]*sseTopic
+	closed bool
+}
+
+// sseTopic 一个主题的订阅者和最近的事件
+type sseTopic struct {
+	clients map[*sseClient]struct{}
+	// buffer 环形缓冲区，start 为最早的事件
+	buffer []sseEvent
+	start  int
+}
+
+type sseEvent struct {
+	id    uint64
+	event sse.Event
+}
+
+type sseClient struct {
+	events chan sseEvent
+	done   chan struct{} // 被代理断开时关闭
+	once   sync.Once
+}
+
+func (client *sseClient) close() {
+	client.once.Do(func() { close(client.done) })
+}
+
+// NewSSEBroker 创建 SSEBroker
+func NewSSEBroker(opts SSEBrokerOptions) *SSEBroker {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 100
+	}
+	if opts.KeepAlive == 0 {
+		opts.KeepAlive = 15 * time.Second
+	}
+	if opts.ClientBuffer <= 0 {
+		opts.ClientBuffer = 64
+	}
+	return &SSEBroker{opts: opts, topics: make(map[string]*sseTopic)}
+}
+
+// Publish 向主题推送事件，返回事件的 id，data 为结构体、切片或者 map 时按 JSON 编码
+func (b *SSEBroker) Publish(topic, event string, data interface{}) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return "", ErrSSEBrokerClosed
+	}
+	b.lastID++
+	id := strconv.FormatUint(b.lastID, 10)
+	ev := sseEvent{id: b.lastID, event: sse.Event{Id: id, Event: event, Data: data}}
+
+	t := b.topic(topic)
+	if len(t.buffer) < b.opts.BufferSize {
+		t.buffer = append(t.buffer, ev)
+	} else {
+		t.buffer[t.start] = ev
+		t.start = (t.start + 1) % len(t.buffer)
+	}
+	for client := range t.clients {
+		select {
+		case client.events <- ev:
+		default:
+			// 客户端处理不过来，断开后由它带着 Last-Event-ID 重连
+			b.unsubscribe(client)
+			client.close()
+		}
+	}
+	return id, nil
+}
+
+// Subscribe 把请求订阅到 topics，持续推送事件，直到请求的 context 取消、客户端被断开或者 SSEBroker 关闭
+// 请求头中有 Last-Event-ID 时，先补发缓冲区中 id 大于它的事件
+func (b *SSEBroker) Subscribe(c *Context, topics ...string) error {
+	client := &sseClient{events: make(chan sseEvent, b.opts.ClientBuffer), done: make(chan struct{})}
+	lastID, _ := strconv.ParseUint(c.GetHeader("Last-Event-ID"), 10, 64)
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrSSEBrokerClosed
+	}
+	// 注册和读取缓冲区在同一个锁中，补发的事件和之后推送的事件不会重复也不会遗漏
+	var replay []sseEvent
+	for _, topic := range topics {
+		t := b.topic(topic)
+		t.clients[client] = struct{}{}
+		if lastID == 0 {
+			continue
+		}
+		for i := range t.buffer {
+			if ev := t.buffer[(t.start+i)%len(t.buffer)]; ev.id > lastID {
+				replay = append(replay, ev)
+			}
+		}
+	}
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		b.unsubscribe(client)
+		b.mu.Unlock()
+	}()
+	sort.Slice(replay, func(i, j int) bool { return replay[i].id < replay[j].id })
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no") // 禁止 nginx 缓冲
+	c.Status(http.StatusOK)
+	if b.opts.Retry > 0 {
+		if _, err := io.WriteString(c.Writer, "retry:"+strconv.FormatInt(b.opts.Retry.Milliseconds(), 10)+"\n\n"); err != nil {
+			return err
+		}
+	}
+	for _, ev := range replay {
+		if err := sse.Encode(c.Writer, ev.event); err != nil {
+			return err
+		}
+	}
+	c.Writer.Flush()
+
+	var keepAlive <-chan time.Time
+	if b.opts.KeepAlive > 0 {
+		ticker := time.NewTicker(b.opts.KeepAlive)
+		defer ticker.Stop()
+		keepAlive = ticker.C
+	}
+	done := c.Request.Context().Done()
+	for {
+		select {
+		case <-done:
+			return nil
+		case <-client.done:
+			return nil
+		case ev := <-client.events:
+			if err := sse.Encode(c.Writer, ev.event); err != nil {
+				return err
+			}
+		case <-keepAlive:
+			if _, err := io.WriteString(c.Writer, ": keep-alive\n\n"); err != nil {
+				return err
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+// Handler 返回把请求订阅到 topics 的处理函数
+func (b *SSEBroker) Handler(topics ...string) HandlerFunc {
+	return func(c *Context) {
+		if err := b.Subscribe(c, topics...); err != nil {
+			_ = c.Error(err)
+		}
+	}
+}
+
+// Close 关闭 SSEBroker，断开所有的客户端，之后的 Publish 和 Subscribe 返回 ErrSSEBrokerClosed
+func (b *SSEBroker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for _, t := range b.topics {
+		for client := range t.clients {
+			client.close()
+		}
+	}
+	b.topics = make(map[string]*sseTopic)
+}
+
+// topic 返回主题，不存在时创建，调用方需要持有锁
+func (b *SSEBroker) topic(name string) *sseTopic {
+	t, ok := b.topics[name]
+	if !ok {
+		t = &sseTopic{clients: make(map[*sseClient]struct{})}
+		b.topics[name] = t
+	}
+	return t
+}
+
+// unsubscribe 取消客户端的所有订阅，调用方需要持有锁
+func (b *SSEBroker) unsubscribe(client *sseClient) {
+	for _, t := range b.topics {
+		delete(t.clients, client)
+	}
+}
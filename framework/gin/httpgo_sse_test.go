@@ -0,0 +1,97 @@
+package gin
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readSSE 读取 n 个以空行结尾的块
+func readSSE(t *testing.T, r *bufio.Reader, n int) []string {
+	var blocks []string
+	var sb strings.Builder
+	for len(blocks) < n {
+		line, err := r.ReadString('\n')
+		if !assert.NoError(t, err) {
+			return blocks
+		}
+		if line == "\n" {
+			blocks = append(blocks, sb.String())
+			sb.Reset()
+			continue
+		}
+		sb.WriteString(line)
+	}
+	return blocks
+}
+
+func TestSSEBroker(t *testing.T) {
+	broker := NewSSEBroker(SSEBrokerOptions{BufferSize: 2, KeepAlive: 50 * time.Millisecond, Retry: 3 * time.Second})
+	defer broker.Close()
+	router := New()
+	router.GET("/events", broker.Handler("news", "sport"))
+	server := httptest.NewServer(router.Handler())
+	defer server.Close()
+
+	for _, msg := range []string{"a", "b", "c"} {
+		_, err := broker.Publish("news", "message", msg)
+		assert.NoError(t, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/events", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	r := bufio.NewReader(resp.Body)
+	// 缓冲区只保留最近的 2 个事件
+	assert.Equal(t, []string{
+		"retry:3000\n",
+		"id:2\nevent:message\ndata:b\n",
+		"id:3\nevent:message\ndata:c\n",
+	}, readSSE(t, r, 3))
+
+	id, err := broker.Publish("sport", "score", H{"home": 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "4", id)
+	assert.Equal(t, []string{"id:4\nevent:score\ndata:{\"home\":1}\n"}, readSSE(t, r, 1))
+	assert.Equal(t, []string{": keep-alive\n"}, readSSE(t, r, 1))
+
+	cancel()
+	assert.Eventually(t, func() bool {
+		broker.mu.Lock()
+		defer broker.mu.Unlock()
+		return len(broker.topics["news"].clients) == 0 && len(broker.topics["sport"].clients) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestSSEBrokerSlowClientAndClose(t *testing.T) {
+	broker := NewSSEBroker(SSEBrokerOptions{ClientBuffer: 1, KeepAlive: -1})
+	client := &sseClient{events: make(chan sseEvent, 1), done: make(chan struct{})}
+	broker.topic("news").clients[client] = struct{}{}
+
+	_, _ = broker.Publish("news", "", "1")
+	_, _ = broker.Publish("news", "", "2")
+	select {
+	case <-client.done:
+	default:
+		t.Fatal("slow client should be disconnected")
+	}
+	assert.Empty(t, broker.topics["news"].clients)
+
+	broker.Close()
+	_, err := broker.Publish("news", "", "3")
+	assert.ErrorIs(t, err, ErrSSEBrokerClosed)
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	assert.ErrorIs(t, broker.Subscribe(c, "news"), ErrSSEBrokerClosed)
+}
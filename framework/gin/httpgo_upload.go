@@ -0,0 +1,101 @@
+package gin
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// 上传相关的错误
+var (
+	ErrRequestTooLarge      = RegisterError(41300, http.StatusRequestEntityTooLarge, "request entity too large")
+	ErrUnsupportedMediaType = RegisterError(41500, http.StatusUnsupportedMediaType, "unsupported media type")
+)
+
+// MaxBodyBytes 中间件，限制路由的请求体大小，单位为字节
+// Content-Length 超过 n 时直接以 ErrRequestTooLarge 结束，否则读取超过 n 字节时返回 *http.MaxBytesError
+//
+//	router.POST("/avatar", gin.MaxBodyBytes(2<<20), handler)
+func MaxBodyBytes(n int64) HandlerFunc {
+	return func(c *Context) {
+		if c.Request.ContentLength > n {
+			c.Fail(ErrRequestTooLarge)
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, n)
+	}
+}
+
+// UploadOptions UploadFile 的校验选项
+type UploadOptions struct {
+	// MaxSize 单个文件的最大字节数，为 0 时不限制
+	MaxSize int64
+	// AllowedTypes 允许的 MIME 类型，根据文件内容嗅探，不信任客户端的 Content-Type 和扩展名
+	// 支持 "image/*" 形式的通配，为空时不限制
+	AllowedTypes []string
+}
+
+// UploadedFile 通过校验的上传文件
+type UploadedFile struct {
+	*multipart.FileHeader
+	// MIME 嗅探到的 MIME 类型
+	MIME string
+	// Extension 与 MIME 对应的扩展名，例如 ".png"
+	Extension string
+}
+
+// UploadFile 读取表单中名为 name 的文件，并根据 opts 校验文件大小和嗅探到的类型
+// 返回的错误为 AppError，可以直接交给 Fail：请求体或者文件过大时为 ErrRequestTooLarge，
+// 类型不允许时为 ErrUnsupportedMediaType，其他错误为 ErrBadRequest
+func (c *Context) UploadFile(name string, opts UploadOptions) (*UploadedFile, error) {
+	fh, err := c.FormFile(name)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return nil, ErrRequestTooLarge.Wrap(err)
+		}
+		return nil, ErrBadRequest.Wrap(err)
+	}
+	if opts.MaxSize > 0 && fh.Size > opts.MaxSize {
+		return nil, ErrRequestTooLarge.Wrap(fmt.Errorf("file %q is %d bytes, limit is %d", fh.Filename, fh.Size, opts.MaxSize))
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return nil, ErrBadRequest.Wrap(err)
+	}
+	defer f.Close()
+	mt, err := mimetype.DetectReader(f)
+	if err != nil {
+		return nil, ErrBadRequest.Wrap(err)
+	}
+	if !allowedMIME(mt, opts.AllowedTypes) {
+		return nil, ErrUnsupportedMediaType.Wrap(fmt.Errorf("file %q is %s", fh.Filename, mt))
+	}
+	return &UploadedFile{FileHeader: fh, MIME: mt.String(), Extension: mt.Extension()}, nil
+}
+
+// allowedMIME mt 是否在 allowed 中，allowed 为空时都允许
+// 只比较嗅探到的类型本身，不比较它的父类型，例如允许 text/plain 并不会允许 text/html
+func allowedMIME(mt *mimetype.MIME, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	mediaType, _, _ := strings.Cut(mt.String(), ";")
+	for _, a := range allowed {
+		if prefix, ok := strings.CutSuffix(a, "/*"); ok {
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return true
+			}
+			continue
+		}
+		if mt.Is(a) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,479 @@
+package gin
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/gothms/httpgo/framework/contract"
+)
+
+// TusResumable 支持的 tus 协议版本
+const TusResumable = "1.0.0"
+
+// sniffLength 嗅探类型需要的字节数，与 mimetype 默认读取的长度相同
+const sniffLength = 3072
+
+// 断点续传相关的错误
+var (
+	ErrUploadConflict  = RegisterError(40900, http.StatusConflict, "upload offset mismatch")
+	ErrTusVersion      = RegisterError(41200, http.StatusPreconditionFailed, "unsupported tus version")
+	errInvalidUploadID = errors.New("invalid upload id")
+)
+
+// ResumableUploadOptions 断点续传的选项
+type ResumableUploadOptions struct {
+	// Folder 存储目录，为空时使用容器中 App 服务的 StorageFolder()/upload
+	Folder string
+	// MaxSize 单个文件的最大字节数，为 0 时不限制
+	MaxSize int64
+	// AllowedTypes 允许的 MIME 类型，与 UploadOptions.AllowedTypes 相同
+	// 收到足够嗅探的数据后校验，不允许的上传会被删除
+	AllowedTypes []string
+	// OnComplete 在完成上传的 PATCH 请求中调用，没有写入响应时返回 204
+	OnComplete func(c *Context, info *ResumableUploadInfo)
+	// Expiration 未完成的上传超过这个时间没有收到数据就会被删除，同时启用 tus 的 expiration 扩展
+	// 过期的上传在访问时删除，创建上传时也会在后台清理存储目录中过期的上传，每个 Expiration 最多清理一次
+	// 为 0 时不会过期，客户端放弃的上传会一直保留在存储目录中；已经完成的上传不会被删除，需要在 OnComplete 中处理
+	Expiration time.Duration
+}
+
+// ResumableUploadInfo 一个上传的信息，保存在存储目录的 <id>.info 中
+type ResumableUploadInfo struct {
+	ID        string            `json:"id"`
+	Size      int64             `json:"size"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	MIME      string            `json:"mime,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	// Offset 已经接收的字节数
+	Offset int64 `json:"-"`
+	// Path 数据文件的路径
+	Path string `json:"-"`
+	// UpdatedAt 最后一次收到数据的时间，为数据文件的修改时间
+	UpdatedAt time.Time `json:"-"`
+}
+
+// resumableUploads 基于本地文件的 tus 协议实现
+type resumableUploads struct {
+	opts     ResumableUploadOptions
+	folderMu sync.Mutex
+	folder   string
+
+	locksMu sync.Mutex
+	locks   map[string]*uploadLock // 上传 id 对应的锁，同一个上传的请求串行处理
+
+	cleanMu   sync.Mutex
+	cleanedAt time.Time // 上一次清理过期上传的时间
+}
+
+// uploadLock 一个上传的锁，refs 为持有和等待这个锁的请求数，为 0 时删除
+type uploadLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// ResumableUpload 在 relativePath 下注册 tus 协议（https://tus.io/protocols/resumable-upload）的断点续传路由，
+// 支持 creation 和 termination 扩展，返回注册了这些路由的路由组：
+//
+//	OPTIONS relativePath       协议信息
+//	POST    relativePath       创建上传，Upload-Length 为文件大小，返回 Location
+//	HEAD    relativePath/:id   查询已经接收的 Upload-Offset
+//	PATCH   relativePath/:id   从 Upload-Offset 开始追加数据
+//	DELETE  relativePath/:id   删除上传
+func (group *RouterGroup) ResumableUpload(relativePath string, opts ResumableUploadOptions, handlers ...HandlerFunc) *RouterGroup {
+	u := &resumableUploads{opts: opts}
+	g := group.Group(relativePath, append(handlers, tusHeaders())...)
+	g.OPTIONS("", u.options)
+	g.POST("", u.create)
+	g.HEAD("/:id", u.head)
+	g.PATCH("/:id", u.patch)
+	g.DELETE("/:id", u.terminate)
+	return g
+}
+
+// tusHeaders 设置协议响应头，除 OPTIONS 外要求请求的 Tus-Resumable 与服务端版本一致
+func tusHeaders() HandlerFunc {
+	return func(c *Context) {
+		c.Header("Tus-Resumable", TusResumable)
+		if c.Request.Method == http.MethodOptions {
+			return
+		}
+		if c.GetHeader("Tus-Resumable") != TusResumable {
+			c.Header("Tus-Version", TusResumable)
+			c.Fail(ErrTusVersion)
+		}
+	}
+}
+
+func (u *resumableUploads) options(c *Context) {
+	c.Header("Tus-Version", TusResumable)
+	if u.opts.Expiration > 0 {
+		c.Header("Tus-Extension", "creation,termination,expiration")
+	} else {
+		c.Header("Tus-Extension", "creation,termination")
+	}
+	if u.opts.MaxSize > 0 {
+		c.Header("Tus-Max-Size", strconv.FormatInt(u.opts.MaxSize, 10))
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (u *resumableUploads) create(c *Context) {
+	size, err := strconv.ParseInt(c.GetHeader("Upload-Length"), 10, 64)
+	if err != nil || size < 0 {
+		c.Fail(ErrBadRequest.WithMessage("invalid Upload-Length"))
+		return
+	}
+	if u.opts.MaxSize > 0 && size > u.opts.MaxSize {
+		c.Fail(ErrRequestTooLarge)
+		return
+	}
+	metadata, err := parseUploadMetadata(c.GetHeader("Upload-Metadata"))
+	if err != nil {
+		c.Fail(ErrBadRequest.WithMessage("invalid Upload-Metadata"))
+		return
+	}
+
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		c.Fail(err)
+		return
+	}
+	info := &ResumableUploadInfo{ID: hex.EncodeToString(b[:]), Size: size, Metadata: metadata, CreatedAt: time.Now()}
+	folder, err := u.dir(c)
+	if err != nil {
+		c.Fail(err)
+		return
+	}
+	u.cleanExpired(folder)
+	info.Path = filepath.Join(folder, info.ID)
+	if err := os.WriteFile(info.Path, nil, 0644); err != nil {
+		c.Fail(err)
+		return
+	}
+	if err := u.save(info); err != nil {
+		c.Fail(err)
+		return
+	}
+
+	c.Header("Location", strings.TrimSuffix(c.Request.URL.Path, "/")+"/"+info.ID)
+	info.UpdatedAt = info.CreatedAt
+	u.expiresHeader(c, info)
+	if size == 0 {
+		u.complete(c, info)
+		if c.Writer.Written() {
+			return
+		}
+	}
+	c.Status(http.StatusCreated)
+}
+
+func (u *resumableUploads) head(c *Context) {
+	info, unlock, err := u.acquire(c)
+	if err != nil {
+		c.Fail(err)
+		return
+	}
+	defer unlock()
+	c.Header("Cache-Control", "no-store")
+	c.Header("Upload-Offset", strconv.FormatInt(info.Offset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(info.Size, 10))
+	u.expiresHeader(c, info)
+	if len(info.Metadata) > 0 {
+		c.Header("Upload-Metadata", formatUploadMetadata(info.Metadata))
+	}
+	c.Status(http.StatusOK)
+}
+
+func (u *resumableUploads) patch(c *Context) {
+	if c.ContentType() != "application/offset+octet-stream" {
+		c.Fail(ErrUnsupportedMediaType)
+		return
+	}
+	info, unlock, err := u.acquire(c)
+	if err != nil {
+		c.Fail(err)
+		return
+	}
+	defer unlock()
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil {
+		c.Fail(ErrBadRequest.WithMessage("invalid Upload-Offset"))
+		return
+	}
+	if offset != info.Offset {
+		c.Fail(ErrUploadConflict)
+		return
+	}
+	remaining := info.Size - info.Offset
+	if c.Request.ContentLength > remaining {
+		c.Fail(ErrRequestTooLarge)
+		return
+	}
+
+	f, err := os.OpenFile(info.Path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		c.Fail(err)
+		return
+	}
+	// 连接中断时保留已经写入的数据，客户端通过 HEAD 查询后继续上传
+	n, copyErr := io.Copy(f, io.LimitReader(c.Request.Body, remaining))
+	if err := f.Close(); err != nil && copyErr == nil {
+		copyErr = err
+	}
+	info.Offset += n
+	info.UpdatedAt = time.Now()
+	c.Header("Upload-Offset", strconv.FormatInt(info.Offset, 10))
+	u.expiresHeader(c, info)
+	if copyErr != nil {
+		c.Fail(copyErr)
+		return
+	}
+
+	if info.MIME == "" && (info.Offset >= sniffLength || info.Offset == info.Size) {
+		if !u.sniff(c, info) {
+			return
+		}
+	}
+	if info.Offset == info.Size && n > 0 {
+		u.complete(c, info)
+	}
+	if !c.Writer.Written() {
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func (u *resumableUploads) terminate(c *Context) {
+	info, unlock, err := u.acquire(c)
+	if err != nil {
+		c.Fail(err)
+		return
+	}
+	defer unlock()
+	u.remove(info)
+	c.Status(http.StatusNoContent)
+}
+
+// sniff 根据已经接收的数据嗅探类型，类型不允许时删除上传并返回 false
+func (u *resumableUploads) sniff(c *Context, info *ResumableUploadInfo) bool {
+	mt, err := mimetype.DetectFile(info.Path)
+	if err != nil {
+		c.Fail(err)
+		return false
+	}
+	if !allowedMIME(mt, u.opts.AllowedTypes) {
+		u.remove(info)
+		c.Fail(ErrUnsupportedMediaType.Wrap(fmt.Errorf("upload %s is %s", info.ID, mt)))
+		return false
+	}
+	info.MIME = mt.String()
+	if err := u.save(info); err != nil {
+		c.Fail(err)
+		return false
+	}
+	return true
+}
+
+func (u *resumableUploads) complete(c *Context, info *ResumableUploadInfo) {
+	if info.MIME == "" && !u.sniff(c, info) {
+		return
+	}
+	if u.opts.OnComplete != nil {
+		u.opts.OnComplete(c, info)
+	}
+}
+
+// dir 存储目录，在使用时才从容器中获取 App 服务，获取或者创建失败时返回错误，下一次请求重新尝试
+func (u *resumableUploads) dir(c *Context) (string, error) {
+	u.folderMu.Lock()
+	defer u.folderMu.Unlock()
+	if u.folder != "" {
+		return u.folder, nil
+	}
+	folder := u.opts.Folder
+	if folder == "" {
+		appService, err := c.Make(contract.AppKey)
+		if err != nil {
+			return "", err
+		}
+		folder = filepath.Join(appService.(contract.App).StorageFolder(), "upload")
+	}
+	if err := os.MkdirAll(folder, os.ModePerm); err != nil {
+		return "", err
+	}
+	u.folder = folder
+	return folder, nil
+}
+
+// acquire 锁定并读取路由参数 id 对应的上传，返回的 unlock 用于解锁，过期的上传会被删除并返回 ErrNotFound
+func (u *resumableUploads) acquire(c *Context) (*ResumableUploadInfo, func(), error) {
+	id := c.Param("id")
+	if b, err := hex.DecodeString(id); err != nil || len(b) != 16 {
+		return nil, nil, ErrNotFound.Wrap(errInvalidUploadID)
+	}
+	folder, err := u.dir(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	unlock := u.lock(id)
+	info, err := u.load(folder, id)
+	if err == nil && u.expired(info) {
+		u.remove(info)
+		err = ErrNotFound.Wrap(fmt.Errorf("upload %s expired", id))
+	}
+	if err != nil {
+		unlock()
+		return nil, nil, err
+	}
+	return info, unlock, nil
+}
+
+// lock 锁定上传 id，返回解锁函数，没有请求持有和等待时删除这个上传的锁
+func (u *resumableUploads) lock(id string) func() {
+	u.locksMu.Lock()
+	if u.locks == nil {
+		u.locks = make(map[string]*uploadLock)
+	}
+	l, ok := u.locks[id]
+	if !ok {
+		l = &uploadLock{}
+		u.locks[id] = l
+	}
+	l.refs++
+	u.locksMu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		u.locksMu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(u.locks, id)
+		}
+		u.locksMu.Unlock()
+	}
+}
+
+// expired 未完成的上传超过 Expiration 没有收到数据
+func (u *resumableUploads) expired(info *ResumableUploadInfo) bool {
+	return u.opts.Expiration > 0 && info.Offset < info.Size && time.Since(info.UpdatedAt) > u.opts.Expiration
+}
+
+// expiresHeader 未完成的上传返回 Upload-Expires
+func (u *resumableUploads) expiresHeader(c *Context, info *ResumableUploadInfo) {
+	if u.opts.Expiration > 0 && info.Offset < info.Size {
+		c.Header("Upload-Expires", info.UpdatedAt.Add(u.opts.Expiration).UTC().Format(http.TimeFormat))
+	}
+}
+
+// cleanExpired 在后台删除 folder 中过期的上传，每个 Expiration 最多执行一次
+func (u *resumableUploads) cleanExpired(folder string) {
+	if u.opts.Expiration <= 0 {
+		return
+	}
+	u.cleanMu.Lock()
+	defer u.cleanMu.Unlock()
+	if time.Since(u.cleanedAt) < u.opts.Expiration {
+		return
+	}
+	u.cleanedAt = time.Now()
+	go func() {
+		entries, err := os.ReadDir(folder)
+		if err != nil {
+			return
+		}
+		for _, entry := range entries {
+			id, ok := strings.CutSuffix(entry.Name(), ".info")
+			if !ok || entry.IsDir() {
+				continue
+			}
+			unlock := u.lock(id)
+			if info, err := u.load(folder, id); err == nil && u.expired(info) {
+				u.remove(info)
+			}
+			unlock()
+		}
+	}()
+}
+
+// load 读取上传的信息，Offset 为数据文件的大小，UpdatedAt 为数据文件的修改时间
+func (u *resumableUploads) load(folder, id string) (*ResumableUploadInfo, error) {
+	content, err := os.ReadFile(filepath.Join(folder, id+".info"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound.Wrap(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	info := &ResumableUploadInfo{}
+	if err := json.Unmarshal(content, info); err != nil {
+		return nil, err
+	}
+	info.Path = filepath.Join(folder, id)
+	stat, err := os.Stat(info.Path)
+	if err != nil {
+		return nil, err
+	}
+	info.Offset = stat.Size()
+	info.UpdatedAt = stat.ModTime()
+	return info, nil
+}
+
+func (u *resumableUploads) save(info *ResumableUploadInfo) error {
+	content, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(info.Path+".info", content, 0644)
+}
+
+func (u *resumableUploads) remove(info *ResumableUploadInfo) {
+	_ = os.Remove(info.Path)
+	_ = os.Remove(info.Path + ".info")
+}
+
+// parseUploadMetadata 解析 Upload-Metadata，格式为逗号分隔的 "key base64(value)"，value 可以省略
+func parseUploadMetadata(header string) (map[string]string, error) {
+	if strings.TrimSpace(header) == "" {
+		return nil, nil
+	}
+	metadata := make(map[string]string)
+	for _, pair := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, errors.New("empty metadata key")
+		}
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, err
+		}
+		metadata[key] = string(decoded)
+	}
+	return metadata, nil
+}
+
+func formatUploadMetadata(metadata map[string]string) string {
+	pairs := make([]string, 0, len(metadata))
+	for key, value := range metadata {
+		if value == "" {
+			pairs = append(pairs, key)
+			continue
+		}
+		pairs = append(pairs, key+" "+base64.StdEncoding.EncodeToString([]byte(value)))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
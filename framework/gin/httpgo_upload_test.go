@@ -0,0 +1,307 @@
+package gin
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gothms/httpgo/framework"
+	"github.com/gothms/httpgo/framework/provider/app"
+	"github.com/stretchr/testify/assert"
+)
+
+var pngContent = append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 4000)...)
+
+func multipartRequest(t *testing.T, field, filename string, content []byte) *http.Request {
+	buf := new(bytes.Buffer)
+	mw := multipart.NewWriter(buf)
+	w, err := mw.CreateFormFile(field, filename)
+	assert.NoError(t, err)
+	_, _ = w.Write(content)
+	assert.NoError(t, mw.Close())
+	req := httptest.NewRequest(http.MethodPost, "/upload", buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestMaxBodyBytes(t *testing.T) {
+	router := New()
+	var readErr error
+	router.POST("/echo", MaxBodyBytes(10), func(c *Context) {
+		_, readErr = io.ReadAll(c.Request.Body)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(strings.Repeat("a", 20))))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":41300`)
+
+	// 没有 Content-Length 时在读取时限制
+	req := httptest.NewRequest(http.MethodPost, "/echo", io.MultiReader(strings.NewReader(strings.Repeat("a", 20))))
+	req.ContentLength = -1
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	var maxErr *http.MaxBytesError
+	assert.ErrorAs(t, readErr, &maxErr)
+}
+
+func TestUploadFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    UploadOptions
+		field   string
+		content []byte
+		err     error
+	}{
+		{"allowed", UploadOptions{AllowedTypes: []string{"image/*"}}, "file", pngContent, nil},
+		{"exact type", UploadOptions{AllowedTypes: []string{"image/png"}, MaxSize: 8000}, "file", pngContent, nil},
+		{"not allowed", UploadOptions{AllowedTypes: []string{"application/pdf", "text/plain"}}, "file", pngContent, ErrUnsupportedMediaType},
+		{"html is not text/plain", UploadOptions{AllowedTypes: []string{"text/plain"}}, "file", []byte("<html><body>x</body></html>"), ErrUnsupportedMediaType},
+		{"too large", UploadOptions{MaxSize: 100}, "file", pngContent, ErrRequestTooLarge},
+		{"missing", UploadOptions{}, "other", pngContent, ErrBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := CreateTestContext(httptest.NewRecorder())
+			c.Request = multipartRequest(t, tt.field, "avatar.txt", tt.content)
+			file, err := c.UploadFile("file", tt.opts)
+			if tt.err != nil {
+				assert.True(t, errors.Is(err, tt.err), "%v", err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "image/png", file.MIME)
+			assert.Equal(t, ".png", file.Extension)
+			assert.Equal(t, "avatar.txt", file.Filename)
+		})
+	}
+
+	router := New()
+	router.POST("/upload", MaxBodyBytes(1000), func(c *Context) {
+		if _, err := c.UploadFile("file", UploadOptions{}); err != nil {
+			c.Fail(err)
+		}
+	})
+	w := httptest.NewRecorder()
+	req := multipartRequest(t, "file", "a.png", pngContent)
+	req.ContentLength = -1
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func tusRequest(router http.Handler, method, path string, body []byte, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	req.Header.Set("Tus-Resumable", TusResumable)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestResumableUpload(t *testing.T) {
+	folder := t.TempDir()
+	var completed *ResumableUploadInfo
+	router := New()
+	router.ResumableUpload("/files", ResumableUploadOptions{
+		Folder:       folder,
+		MaxSize:      1 << 20,
+		AllowedTypes: []string{"image/png"},
+		OnComplete: func(c *Context, info *ResumableUploadInfo) {
+			completed = info
+		},
+	})
+
+	w := tusRequest(router, http.MethodOptions, "/files", nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "creation,termination", w.Header().Get("Tus-Extension"))
+	assert.Equal(t, "1048576", w.Header().Get("Tus-Max-Size"))
+
+	w = tusRequest(router, http.MethodPost, "/files", nil, "Upload-Length", "2097152")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	metadata := "filename " + base64.StdEncoding.EncodeToString([]byte("a.png")) + ",private"
+	w = tusRequest(router, http.MethodPost, "/files", nil, "Upload-Length", strconv.Itoa(len(pngContent)), "Upload-Metadata", metadata)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	location := w.Header().Get("Location")
+	assert.True(t, strings.HasPrefix(location, "/files/"))
+
+	patch := func(offset int, chunk []byte) *httptest.ResponseRecorder {
+		return tusRequest(router, http.MethodPatch, location, chunk,
+			"Content-Type", "application/offset+octet-stream", "Upload-Offset", strconv.Itoa(offset))
+	}
+	w = patch(0, pngContent[:100])
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "100", w.Header().Get("Upload-Offset"))
+
+	w = tusRequest(router, http.MethodHead, location, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "100", w.Header().Get("Upload-Offset"))
+	assert.Equal(t, strconv.Itoa(len(pngContent)), w.Header().Get("Upload-Length"))
+	assert.Equal(t, "filename YS5wbmc=,private", w.Header().Get("Upload-Metadata"))
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+	assert.Equal(t, http.StatusConflict, patch(50, pngContent[50:]).Code)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, patch(100, append(pngContent[100:], 0)).Code)
+	w = tusRequest(router, http.MethodPatch, location, pngContent[100:], "Upload-Offset", "100")
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	w = tusRequest(router, http.MethodHead, location, nil, "Tus-Resumable", "0.2.2")
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Equal(t, TusResumable, w.Header().Get("Tus-Version"))
+
+	w = patch(100, pngContent[100:])
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, strconv.Itoa(len(pngContent)), w.Header().Get("Upload-Offset"))
+	if assert.NotNil(t, completed) {
+		assert.Equal(t, "image/png", completed.MIME)
+		assert.Equal(t, map[string]string{"filename": "a.png", "private": ""}, completed.Metadata)
+		content, err := os.ReadFile(completed.Path)
+		assert.NoError(t, err)
+		assert.Equal(t, pngContent, content)
+		assert.Equal(t, folder, filepath.Dir(completed.Path))
+	}
+
+	assert.Equal(t, http.StatusNoContent, tusRequest(router, http.MethodDelete, location, nil).Code)
+	assert.Equal(t, http.StatusNotFound, tusRequest(router, http.MethodHead, location, nil).Code)
+	assert.Equal(t, http.StatusNotFound, tusRequest(router, http.MethodHead, "/files/../secret", nil).Code)
+	entries, _ := os.ReadDir(folder)
+	assert.Empty(t, entries)
+}
+
+func TestResumableUploadSniff(t *testing.T) {
+	// 没有设置 Folder 时使用 App 服务的 StorageFolder()/upload
+	base := t.TempDir()
+	container := framework.NewHttpgoContainer()
+	router := New()
+	router.SetContainer(container)
+	router.ResumableUpload("/files", ResumableUploadOptions{AllowedTypes: []string{"image/png"}})
+
+	content := []byte(strings.Repeat("plain text ", 400))
+	// 还没有绑定 App 服务时返回错误，之后的请求重新获取存储目录
+	w := tusRequest(router, http.MethodPost, "/files", nil, "Upload-Length", strconv.Itoa(len(content)*2))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NoError(t, container.Bind(&app.HttpgoAppProvider{BaseFolder: base}))
+	w = tusRequest(router, http.MethodPost, "/files", nil, "Upload-Length", strconv.Itoa(len(content)*2))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	location := w.Header().Get("Location")
+	folder := filepath.Join(base, "storage", "upload")
+	entries, _ := os.ReadDir(folder)
+	assert.Len(t, entries, 2)
+
+	// 收到足够嗅探的数据后拒绝不允许的类型，并删除上传
+	w = tusRequest(router, http.MethodPatch, location, content,
+		"Content-Type", "application/offset+octet-stream", "Upload-Offset", "0")
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	entries, _ = os.ReadDir(folder)
+	assert.Empty(t, entries)
+}
+
+func TestResumableUploadLocks(t *testing.T) {
+	u := &resumableUploads{opts: ResumableUploadOptions{Folder: t.TempDir()}}
+	router := New()
+	router.POST("/files", u.create)
+	router.HEAD("/files/:id", u.head)
+	router.PATCH("/files/:id", u.patch)
+	router.DELETE("/files/:id", u.terminate)
+	locks := func() int {
+		u.locksMu.Lock()
+		defer u.locksMu.Unlock()
+		return len(u.locks)
+	}
+
+	w := tusRequest(router, http.MethodPost, "/files", nil, "Upload-Length", "4")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	location := w.Header().Get("Location")
+	id := strings.TrimPrefix(location, "/files/")
+
+	// 没有请求持有和等待时删除锁，包括客户端放弃的上传
+	assert.Equal(t, http.StatusOK, tusRequest(router, http.MethodHead, location, nil).Code)
+	assert.Equal(t, 0, locks())
+
+	// 有请求等待时保留锁，等待的请求拿到同一个锁
+	unlock := u.lock(id)
+	done := make(chan int)
+	go func() { done <- tusRequest(router, http.MethodHead, location, nil).Code }()
+	for {
+		u.locksMu.Lock()
+		refs := u.locks[id].refs
+		u.locksMu.Unlock()
+		if refs == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, 1, locks())
+	unlock()
+	assert.Equal(t, http.StatusOK, <-done)
+	assert.Equal(t, 0, locks())
+
+	w = tusRequest(router, http.MethodPatch, location, []byte("data"),
+		"Content-Type", "application/offset+octet-stream", "Upload-Offset", "0")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, 0, locks())
+	assert.Equal(t, http.StatusNoContent, tusRequest(router, http.MethodDelete, location, nil).Code)
+	assert.Equal(t, http.StatusNotFound, tusRequest(router, http.MethodHead, location, nil).Code)
+	assert.Equal(t, 0, locks())
+}
+
+func TestResumableUploadExpiration(t *testing.T) {
+	folder := t.TempDir()
+	router := New()
+	router.ResumableUpload("/files", ResumableUploadOptions{Folder: folder, Expiration: time.Hour})
+
+	w := tusRequest(router, http.MethodOptions, "/files", nil)
+	assert.Equal(t, "creation,termination,expiration", w.Header().Get("Tus-Extension"))
+
+	create := func() string {
+		w := tusRequest(router, http.MethodPost, "/files", nil, "Upload-Length", "4")
+		assert.Equal(t, http.StatusCreated, w.Code)
+		expires, err := http.ParseTime(w.Header().Get("Upload-Expires"))
+		assert.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), expires, time.Minute)
+		return w.Header().Get("Location")
+	}
+	// 把数据文件的修改时间改到 Expiration 之前，模拟客户端放弃的上传
+	expire := func(location string) {
+		old := time.Now().Add(-2 * time.Hour)
+		assert.NoError(t, os.Chtimes(filepath.Join(folder, strings.TrimPrefix(location, "/files/")), old, old))
+	}
+
+	// 访问过期的上传时删除
+	location := create()
+	expire(location)
+	assert.Equal(t, http.StatusNotFound, tusRequest(router, http.MethodHead, location, nil).Code)
+	entries, _ := os.ReadDir(folder)
+	assert.Empty(t, entries)
+
+	// 已经完成的上传不会过期
+	completed := create()
+	w = tusRequest(router, http.MethodPatch, completed, []byte("data"),
+		"Content-Type", "application/offset+octet-stream", "Upload-Offset", "0")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Header().Get("Upload-Expires"))
+	expire(completed)
+	assert.Equal(t, http.StatusOK, tusRequest(router, http.MethodHead, completed, nil).Code)
+
+	// 创建上传时在后台清理存储目录中过期的上传
+	abandoned := create()
+	expire(abandoned)
+	u := &resumableUploads{opts: ResumableUploadOptions{Expiration: time.Hour}}
+	u.cleanExpired(folder)
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(folder, strings.TrimPrefix(abandoned, "/files/")+".info"))
+		return os.IsNotExist(err)
+	}, time.Second, 10*time.Millisecond)
+	entries, _ = os.ReadDir(folder)
+	assert.Len(t, entries, 2)
+}
@@ -0,0 +1,296 @@
+package gin
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	"github.com/go-playground/locales/fr"
+	"github.com/go-playground/locales/ja"
+	"github.com/go-playground/locales/zh"
+	"github.com/go-playground/locales/zh_Hant_TW"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	es_translations "github.com/go-playground/validator/v10/translations/es"
+	fr_translations "github.com/go-playground/validator/v10/translations/fr"
+	ja_translations "github.com/go-playground/validator/v10/translations/ja"
+	zh_translations "github.com/go-playground/validator/v10/translations/zh"
+	zh_tw_translations "github.com/go-playground/validator/v10/translations/zh_tw"
+	"github.com/gothms/httpgo/framework/gin/binding"
+)
+
+// localeTranslation 一种语言的 locales 定义和 validator 的默认翻译
+type localeTranslation struct {
+	translator locales.Translator
+	register   func(v *validator.Validate, trans ut.Translator) error
+}
+
+// localeTranslations 内置的翻译，key 为语言，和 Accept-Language 匹配时不区分大小写
+var localeTranslations = map[string]func() localeTranslation{
+	"en":         func() localeTranslation { return localeTranslation{en.New(), en_translations.RegisterDefaultTranslations} },
+	"zh":         func() localeTranslation { return localeTranslation{zh.New(), zh_translations.RegisterDefaultTranslations} },
+	"zh_Hant_TW": func() localeTranslation { return localeTranslation{zh_Hant_TW.New(), zh_tw_translations.RegisterDefaultTranslations} },
+	"ja":         func() localeTranslation { return localeTranslation{ja.New(), ja_translations.RegisterDefaultTranslations} },
+	"fr":         func() localeTranslation { return localeTranslation{fr.New(), fr_translations.RegisterDefaultTranslations} },
+	"es":         func() localeTranslation { return localeTranslation{es.New(), es_translations.RegisterDefaultTranslations} },
+}
+
+// FieldError 一个字段的校验错误
+type FieldError struct {
+	// Field 字段的路径，使用 json 标签中的名字，例如 user.emails[0]
+	Field string `json:"field"`
+	// Rule 校验失败的规则，例如 required、max
+	Rule string `json:"rule"`
+	// Param 规则的参数，例如 max=10 中的 10
+	Param string `json:"param,omitempty"`
+	// Message 翻译后的错误信息
+	Message string `json:"message"`
+}
+
+// FieldErrors 校验失败的字段列表
+type FieldErrors []FieldError
+
+func (errs FieldErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, e := range errs {
+		messages[i] = e.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// ErrorTranslator 把 validator 的校验错误翻译为 FieldErrors，根据 Accept-Language 选择语言
+type ErrorTranslator struct {
+	uni *ut.UniversalTranslator
+}
+
+// NewErrorTranslator 为 validate 注册 fallback 和 locales 中语言的翻译，Accept-Language 不匹配任何语言时使用 fallback
+// 支持的语言：en、zh、zh_Hant_TW、ja、fr、es
+// 同时会让 validate 使用 json 标签中的名字作为字段名，没有 json 标签时使用 form 标签，
+// validator 会缓存结构体的字段名，需要在使用 validate 校验之前创建
+func NewErrorTranslator(validate *validator.Validate, fallback string, locales ...string) (*ErrorTranslator, error) {
+	validate.RegisterTagNameFunc(fieldTagName)
+
+	fb, ok := localeTranslations[fallback]
+	if !ok {
+		return nil, errors.New("gin: validation locale " + fallback + " not supported")
+	}
+	uni := ut.New(fb().translator)
+	for _, locale := range append([]string{fallback}, locales...) {
+		newTranslation, ok := localeTranslations[locale]
+		if !ok {
+			return nil, errors.New("gin: validation locale " + locale + " not supported")
+		}
+		lt := newTranslation()
+		if err := uni.AddTranslator(lt.translator, true); err != nil {
+			return nil, err
+		}
+		trans, _ := uni.GetTranslator(locale)
+		if err := lt.register(validate, trans); err != nil {
+			return nil, err
+		}
+	}
+	return &ErrorTranslator{uni: uni}, nil
+}
+
+// Translate 把 err 中的校验错误翻译为 acceptLanguage 中最匹配的语言，err 不是校验错误时返回 false
+// t 为 nil 时使用 validator 的英文错误信息
+func (t *ErrorTranslator) Translate(err error, acceptLanguage string) (FieldErrors, bool) {
+	var trans ut.Translator
+	if t != nil {
+		trans, _ = t.uni.FindTranslator(acceptLocales(acceptLanguage)...)
+	}
+	return translateErrors(err, "", trans)
+}
+
+func translateErrors(err error, prefix string, trans ut.Translator) (FieldErrors, bool) {
+	var sliceErrs binding.SliceValidationError
+	if errors.As(err, &sliceErrs) {
+		var out FieldErrors
+		for i, e := range sliceErrs {
+			errs, ok := translateErrors(e, prefix+"["+strconv.Itoa(i)+"]", trans)
+			if !ok {
+				return nil, false
+			}
+			out = append(out, errs...)
+		}
+		return out, true
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil, false
+	}
+	out := make(FieldErrors, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		field := fe.Namespace()
+		// 去掉最外层的结构体名
+		if i := strings.IndexByte(field, '.'); i >= 0 {
+			field = field[i+1:]
+		}
+		if prefix != "" {
+			field = prefix + "." + field
+		}
+		message := fe.Error()
+		if trans != nil {
+			message = fe.Translate(trans)
+		}
+		out = append(out, FieldError{Field: field, Rule: fe.Tag(), Param: fe.Param(), Message: message})
+	}
+	return out, true
+}
+
+// acceptLocales 按权重从高到低返回 Accept-Language 中的语言，例如 zh-CN 会返回 zh_CN 和 zh
+func acceptLocales(acceptLanguage string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		tags = append(tags, weighted{tag: tag, q: q})
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	var result []string
+	for _, w := range tags {
+		tag := strings.ReplaceAll(w.tag, "-", "_")
+		lower := strings.ToLower(tag)
+		// 繁体中文
+		if lower == "zh_tw" || lower == "zh_hk" || strings.HasPrefix(lower, "zh_hant") {
+			result = append(result, "zh_Hant_TW")
+		}
+		result = append(result, tag)
+		if base, _, ok := strings.Cut(tag, "_"); ok {
+			result = append(result, base)
+		}
+	}
+	return result
+}
+
+// fieldTagName 字段在校验错误中的名字，依次使用 json、form 标签和字段名
+func fieldTagName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+var (
+	errorTranslatorOnce sync.Once
+	errorTranslatorErr  error
+	// defaultErrorTranslator UseErrorTranslator 创建的全局错误翻译，Engine 没有单独设置时使用
+	defaultErrorTranslator atomic.Pointer[ErrorTranslator]
+)
+
+// UseErrorTranslator 为默认的校验器 binding.Validator 注册 fallback 和 locales 的翻译，作为所有 Engine 的 BindAndValidate 的错误翻译
+// 注意这是全局的设置：默认的校验器之后会使用 json、form 标签中的名字作为字段名，影响所有的 ShouldBind；
+// 向校验器注册翻译和校验同时进行并不安全，所以只有第一次成功的调用生效，之后的调用不再修改校验器，直接返回 nil。
+// validator 会缓存结构体的字段名，需要在处理请求之前调用，例如在 main 中：
+//
+//	if err := gin.UseErrorTranslator("en", "zh"); err != nil {
+//		panic(err)
+//	}
+func UseErrorTranslator(fallback string, locales ...string) error {
+	for _, locale := range append([]string{fallback}, locales...) {
+		if _, ok := localeTranslations[locale]; !ok {
+			return errors.New("gin: validation locale " + locale + " not supported")
+		}
+	}
+	errorTranslatorOnce.Do(func() {
+		validate, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			errorTranslatorErr = errors.New("gin: binding.Validator is not a *validator.Validate")
+			return
+		}
+		translator, err := NewErrorTranslator(validate, fallback, locales...)
+		if err != nil {
+			errorTranslatorErr = err
+			return
+		}
+		defaultErrorTranslator.Store(translator)
+	})
+	return errorTranslatorErr
+}
+
+// SetErrorTranslator 设置这个 Engine 的 BindAndValidate 使用的错误翻译，translator 需要和 binding.Validator 使用同一个校验器
+func (engine *Engine) SetErrorTranslator(translator *ErrorTranslator) {
+	engine.errorTranslator = translator
+}
+
+// ErrorTranslator 返回 BindAndValidate 使用的错误翻译，没有通过 SetErrorTranslator 设置时使用 UseErrorTranslator 创建的全局错误翻译，
+// 都没有时为 nil，此时错误信息为 validator 的英文信息，字段名为结构体的字段名
+func (engine *Engine) ErrorTranslator() *ErrorTranslator {
+	if engine.errorTranslator != nil {
+		return engine.errorTranslator
+	}
+	return defaultErrorTranslator.Load()
+}
+
+// BindAndValidate 根据 Content-Type 绑定并校验请求参数，失败时写入错误响应并终止后续的处理函数
+// 校验失败时状态码为 Engine.ValidationStatus，errors 为根据 Accept-Language 翻译后的 FieldErrors；
+// 其他绑定错误（例如 JSON 格式错误）状态码为 400；响应的格式可以通过 Engine.ValidationRender 修改，
+// 没有修改且开启了 Engine.ProblemDetails 时输出 Problem
+func (c *Context) BindAndValidate(obj any) error {
+	err := c.ShouldBind(obj)
+	if err == nil {
+		return nil
+	}
+
+	status, message := http.StatusBadRequest, err.Error()
+	errs, ok := c.engine.ErrorTranslator().Translate(err, c.GetHeader("Accept-Language"))
+	if ok {
+		status, message = c.engine.ValidationStatus, "validation failed"
+		if status == 0 {
+			status = http.StatusBadRequest
+		}
+	}
+	render := c.engine.ValidationRender
+	if render == nil {
+		render = defaultValidationRender
+		if c.problemDetails() {
+			render = problemValidationRender
+		}
+	}
+	render(c, status, message, errs)
+	c.Abort()
+	return err
+}
+
+// defaultValidationRender 默认的错误响应：{"code": 400, "message": "validation failed", "errors": [...]}
+func defaultValidationRender(c *Context, status int, message string, errs FieldErrors) {
+	c.JSON(status, H{"code": status, "message": message, "errors": errs})
+}
+
+// problemValidationRender 开启了 ProblemDetails 时的错误响应，FieldErrors 在扩展字段 errors 中
+func problemValidationRender(c *Context, status int, message string, errs FieldErrors) {
+	p := NewProblem(status).WithDetail(message)
+	if errs != nil {
+		p.With("errors", errs)
+	}
+	c.AbortWithProblem(p)
+}
@@ -0,0 +1,97 @@
+package gin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+type validationAddress struct {
+	City string `json:"city" binding:"required"`
+}
+
+type validationUser struct {
+	Name    string            `json:"name" binding:"required"`
+	Age     int               `json:"age" binding:"max=150"`
+	Email   string            `form:"email" binding:"omitempty,email"`
+	Address validationAddress `json:"address"`
+}
+
+func TestBindAndValidate(t *testing.T) {
+	assert.Error(t, UseErrorTranslator("xx"))
+	assert.NoError(t, UseErrorTranslator("en", "zh"))
+	router := New()
+	// 全局的错误翻译对所有 Engine 生效，只有第一次调用生效
+	assert.NotNil(t, router.ErrorTranslator())
+	assert.Same(t, router.ErrorTranslator(), New().ErrorTranslator())
+	assert.NoError(t, UseErrorTranslator("ja"))
+	assert.Same(t, router.ErrorTranslator(), New().ErrorTranslator())
+	router.POST("/user", func(c *Context) {
+		var user validationUser
+		if err := c.BindAndValidate(&user); err != nil {
+			return
+		}
+		c.String(http.StatusOK, user.Name)
+	})
+	post := func(body string, headers ...header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/user", strings.NewReader(body))
+		req.Header.Set("Content-Type", MIMEJSON)
+		for _, h := range headers {
+			req.Header.Set(h.Key, h.Value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post(`{"name":"foo","address":{"city":"bj"}}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "foo", w.Body.String())
+
+	w = post(`{"age":200,"email":"x"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"code":400,"message":"validation failed","errors":[
+		{"field":"name","rule":"required","message":"name is a required field"},
+		{"field":"age","rule":"max","param":"150","message":"age must be 150 or less"},
+		{"field":"email","rule":"email","message":"email must be a valid email address"},
+		{"field":"address.city","rule":"required","message":"city is a required field"}]}`, w.Body.String())
+
+	w = post(`{"name":"foo","age":200,"address":{"city":"bj"}}`, header{"Accept-Language", "fr;q=0.5, zh-CN, en;q=0.8"})
+	assert.JSONEq(t, `{"code":400,"message":"validation failed","errors":[
+		{"field":"age","rule":"max","param":"150","message":"age必须小于或等于150"}]}`, w.Body.String())
+
+	w = post(`{"name":`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"errors":null`)
+
+	router.ValidationStatus = http.StatusUnprocessableEntity
+	router.ValidationRender = func(c *Context, status int, message string, errs FieldErrors) {
+		c.JSON(status, H{"error": errs.Error()})
+	}
+	w = post(`{"name":"foo"}`, header{"Accept-Language", "en"})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.JSONEq(t, `{"error":"city is a required field"}`, w.Body.String())
+}
+
+func TestErrorTranslator(t *testing.T) {
+	validate := validator.New()
+	validate.SetTagName("binding")
+	translator, err := NewErrorTranslator(validate, "en", "zh_Hant_TW", "ja")
+	assert.NoError(t, err)
+
+	errs, ok := translator.Translate(validate.Struct(validationUser{Age: 200, Address: validationAddress{City: "x"}}), "zh-TW")
+	assert.True(t, ok)
+	assert.Equal(t, FieldErrors{{Field: "name", Rule: "required", Message: "name為必填欄位"}, {Field: "age", Rule: "max", Param: "150", Message: "age必須小於或等於150"}}, errs)
+
+	_, ok = translator.Translate(assert.AnError, "")
+	assert.False(t, ok)
+
+	_, err = NewErrorTranslator(validator.New(), "xx")
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{"zh_Hant_TW", "zh_Hant", "zh", "en_US", "en"}, acceptLocales("en-US;q=0.9, zh-Hant, *"))
+}
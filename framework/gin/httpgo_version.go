@@ -0,0 +1,188 @@
+package gin
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// APIVersionKey 请求匹配到的 API 版本在 Context 中的 key
+const APIVersionKey = "_gin-gonic/gin/apiversionkey"
+
+// VersionOptions 版本路由组的选项
+type VersionOptions struct {
+	// PathPrefix 版本的路径前缀，例如 "v" 时版本 2 的路由还会注册到 /v2/path，为空时不注册带版本前缀的路由
+	PathPrefix string
+	// Header 携带版本号的请求头，例如 "X-API-Version"，为空时不从请求头中读取
+	Header string
+	// MediaType 携带版本号的媒体类型，例如 "application/vnd.x+json"，
+	// 请求头 Accept: application/vnd.x+json;version=2 表示版本 2，为空时不从 Accept 中读取
+	MediaType string
+	// Default 请求中没有指定版本时使用的版本
+	Default string
+}
+
+// VersionedGroup 版本路由组，同一个路径可以为每个版本注册不同的处理函数
+// 不带版本前缀的路由按 请求头、Accept 的顺序确定版本，都没有指定时使用默认版本
+type VersionedGroup struct {
+	group    *RouterGroup
+	opts     VersionOptions
+	versions map[string]*APIVersion
+	// routes 不带版本前缀的路由，key 为请求方法和相对路径
+	routes map[routeKey]map[string]HandlersChain
+}
+
+// APIVersion 版本路由组中的一个版本
+type APIVersion struct {
+	vg         *VersionedGroup
+	version    string
+	deprecated bool
+	sunset     time.Time
+}
+
+// Versioned 在路由组中创建版本路由组
+func (group *RouterGroup) Versioned(opts VersionOptions) *VersionedGroup {
+	return &VersionedGroup{
+		group:    group,
+		opts:     opts,
+		versions: make(map[string]*APIVersion),
+		routes:   make(map[routeKey]map[string]HandlersChain),
+	}
+}
+
+// Version 返回版本号为 version 的版本，多次调用返回同一个版本
+func (vg *VersionedGroup) Version(version string) *APIVersion {
+	if version == "" {
+		panic("gin: api version can not be empty")
+	}
+	v, ok := vg.versions[version]
+	if !ok {
+		v = &APIVersion{vg: vg, version: version}
+		vg.versions[version] = v
+	}
+	return v
+}
+
+// Deprecate 标记版本已弃用，该版本的响应会带上 Deprecation 响应头，sunset 不为零值时还会带上 Sunset 响应头
+func (v *APIVersion) Deprecate(sunset time.Time) *APIVersion {
+	v.deprecated = true
+	v.sunset = sunset
+	return v
+}
+
+// Handle 注册该版本的路由，handlers 在路由组的中间件之后执行
+func (v *APIVersion) Handle(httpMethod, relativePath string, handlers ...HandlerFunc) *APIVersion {
+	if matched := regEnLetter.MatchString(httpMethod); !matched {
+		panic("http method " + httpMethod + " is not valid")
+	}
+	if len(handlers) == 0 {
+		panic("gin: there must be at least one handler")
+	}
+	vg := v.vg
+	key := routeKey{method: httpMethod, path: relativePath}
+	chain := vg.routes[key]
+	if chain == nil {
+		chain = make(map[string]HandlersChain)
+		vg.routes[key] = chain
+		vg.group.handle(httpMethod, relativePath, HandlersChain{vg.dispatch(chain)})
+	}
+	if _, ok := chain[v.version]; ok {
+		panic("gin: version " + v.version + " of route " + httpMethod + " " + relativePath + " is already registered")
+	}
+	chain[v.version] = handlers
+
+	if vg.opts.PathPrefix != "" {
+		vg.group.handle(httpMethod, "/"+vg.opts.PathPrefix+v.version+relativePath,
+			HandlersChain{func(c *Context) { v.serve(c, handlers) }})
+	}
+	return v
+}
+
+// GET is a shortcut for v.Handle("GET", path, handlers).
+func (v *APIVersion) GET(relativePath string, handlers ...HandlerFunc) *APIVersion {
+	return v.Handle(http.MethodGet, relativePath, handlers...)
+}
+
+// POST is a shortcut for v.Handle("POST", path, handlers).
+func (v *APIVersion) POST(relativePath string, handlers ...HandlerFunc) *APIVersion {
+	return v.Handle(http.MethodPost, relativePath, handlers...)
+}
+
+// PUT is a shortcut for v.Handle("PUT", path, handlers).
+func (v *APIVersion) PUT(relativePath string, handlers ...HandlerFunc) *APIVersion {
+	return v.Handle(http.MethodPut, relativePath, handlers...)
+}
+
+// PATCH is a shortcut for v.Handle("PATCH", path, handlers).
+func (v *APIVersion) PATCH(relativePath string, handlers ...HandlerFunc) *APIVersion {
+	return v.Handle(http.MethodPatch, relativePath, handlers...)
+}
+
+// DELETE is a shortcut for v.Handle("DELETE", path, handlers).
+func (v *APIVersion) DELETE(relativePath string, handlers ...HandlerFunc) *APIVersion {
+	return v.Handle(http.MethodDelete, relativePath, handlers...)
+}
+
+// dispatch 返回不带版本前缀的路由的处理函数，根据请求中的版本选择 chain 中的处理函数
+// 请求了未注册的版本时返回 406，没有指定版本且没有默认版本时返回 404
+func (vg *VersionedGroup) dispatch(chain map[string]HandlersChain) HandlerFunc {
+	return func(c *Context) {
+		version, requested := vg.requestVersion(c.Request)
+		if !requested {
+			version = vg.opts.Default
+		}
+		handlers, ok := chain[version]
+		if !ok {
+			if requested {
+				c.AbortWithStatus(http.StatusNotAcceptable)
+			} else {
+				c.AbortWithStatus(http.StatusNotFound)
+			}
+			return
+		}
+		vg.versions[version].serve(c, handlers)
+	}
+}
+
+// requestVersion 按 请求头、Accept 的顺序读取请求中的版本号
+func (vg *VersionedGroup) requestVersion(req *http.Request) (string, bool) {
+	if vg.opts.Header != "" {
+		if version := strings.TrimSpace(req.Header.Get(vg.opts.Header)); version != "" {
+			return version, true
+		}
+	}
+	if vg.opts.MediaType != "" {
+		for _, accept := range req.Header.Values("Accept") {
+			for _, part := range strings.Split(accept, ",") {
+				mediaType, params, err := mime.ParseMediaType(part)
+				if err != nil || mediaType != vg.opts.MediaType {
+					continue
+				}
+				if version := params["version"]; version != "" {
+					return version, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// serve 设置版本相关的响应头，把该版本的处理函数接在当前处理函数之后执行
+func (v *APIVersion) serve(c *Context, handlers HandlersChain) {
+	if v.deprecated {
+		c.Header("Deprecation", "true")
+		if !v.sunset.IsZero() {
+			c.Header("Sunset", v.sunset.UTC().Format(http.TimeFormat))
+		}
+	}
+	c.Set(APIVersionKey, v.version)
+
+	next := int(c.index) + 1
+	if next+len(handlers) >= int(abortIndex) {
+		panic("too many handlers")
+	}
+	chain := make(HandlersChain, next, next+len(handlers))
+	copy(chain, c.handlers[:next])
+	c.handlers = append(chain, handlers...)
+}
@@ -0,0 +1,77 @@
+package gin
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionedGroup(t *testing.T) {
+	router := New()
+	api := router.Group("/api", func(c *Context) {
+		c.Header("X-Group", "api")
+		c.Next()
+	})
+	vg := api.Versioned(VersionOptions{
+		PathPrefix: "v",
+		Header:     "X-API-Version",
+		MediaType:  "application/vnd.x+json",
+		Default:    "2",
+	})
+	sunset := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	vg.Version("1").Deprecate(sunset).GET("/user/:id", func(c *Context) {
+		c.String(http.StatusOK, "v1 "+c.Param("id"))
+	})
+	vg.Version("2").GET("/user/:id", func(c *Context) {
+		c.Next()
+		c.String(http.StatusOK, " "+c.Param("id"))
+	}, func(c *Context) {
+		c.String(http.StatusOK, "v"+c.GetString(APIVersionKey))
+	})
+
+	tests := []struct {
+		path    string
+		headers []header
+		code    int
+		body    string
+	}{
+		{"/api/user/1", nil, http.StatusOK, "v2 1"},
+		{"/api/user/1", []header{{"X-Api-Version", "1"}}, http.StatusOK, "v1 1"},
+		{"/api/user/1", []header{{"Accept", "text/html, application/vnd.x+json; version=1"}}, http.StatusOK, "v1 1"},
+		{"/api/user/1", []header{{"Accept", "application/vnd.y+json; version=1"}}, http.StatusOK, "v2 1"},
+		{"/api/user/1", []header{{"X-Api-Version", "3"}}, http.StatusNotAcceptable, ""},
+		{"/api/v1/user/1", []header{{"X-Api-Version", "2"}}, http.StatusOK, "v1 1"},
+		{"/api/v2/user/1", nil, http.StatusOK, "v2 1"},
+	}
+	for _, tt := range tests {
+		w := PerformRequest(router, http.MethodGet, tt.path, tt.headers...)
+		assert.Equal(t, tt.code, w.Code, tt.path)
+		assert.Equal(t, tt.body, w.Body.String(), tt.path)
+		if tt.code == http.StatusOK {
+			assert.Equal(t, "api", w.Header().Get("X-Group"))
+		}
+	}
+
+	w := PerformRequest(router, http.MethodGet, "/api/v1/user/1")
+	assert.Equal(t, "true", w.Header().Get("Deprecation"))
+	assert.Equal(t, "Tue, 01 Jan 2030 00:00:00 GMT", w.Header().Get("Sunset"))
+	w = PerformRequest(router, http.MethodGet, "/api/user/1")
+	assert.Empty(t, w.Header().Get("Deprecation"))
+}
+
+func TestVersionedGroupWithoutDefault(t *testing.T) {
+	router := New()
+	vg := router.Versioned(VersionOptions{Header: "X-API-Version"})
+	vg.Version("1").GET("/ping", func(c *Context) { c.String(http.StatusOK, "pong") })
+
+	w := PerformRequest(router, http.MethodGet, "/ping")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = PerformRequest(router, http.MethodGet, "/v1/ping")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	assert.Panics(t, func() {
+		vg.Version("1").GET("/ping", func(c *Context) {})
+	})
+}
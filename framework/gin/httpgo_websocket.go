@@ -0,0 +1,782 @@
+package gin
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// WebSocket 消息类型，见 RFC 6455 5.2
+const (
+	WebSocketTextMessage   = 1
+	WebSocketBinaryMessage = 2
+	WebSocketCloseMessage  = 8
+	WebSocketPingMessage   = 9
+	WebSocketPongMessage   = 10
+)
+
+// WebSocket 关闭状态码，见 RFC 6455 7.4.1
+const (
+	WebSocketCloseNormalClosure      = 1000
+	WebSocketCloseGoingAway          = 1001
+	WebSocketCloseProtocolError      = 1002
+	WebSocketCloseUnsupportedData    = 1003
+	WebSocketCloseNoStatusReceived   = 1005
+	WebSocketCloseAbnormalClosure    = 1006
+	WebSocketCloseInvalidPayloadData = 1007
+	WebSocketClosePolicyViolation    = 1008
+	WebSocketCloseMessageTooBig      = 1009
+	WebSocketCloseInternalServerErr  = 1011
+)
+
+const (
+	websocketGUID             = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	defaultWebSocketReadLimit = 32 << 20 // 32 MB
+	defaultWebSocketTimeout   = 10 * time.Second
+	// websocketCloseTimeout 发送关闭帧后等待对端回应的时间
+	websocketCloseTimeout = 5 * time.Second
+	websocketDeflate      = "permessage-deflate"
+	websocketDeflateReply = "permessage-deflate; server_no_context_takeover; client_no_context_takeover"
+)
+
+// ErrWebSocketCloseSent 已经发送了关闭帧，不能再写入消息
+var ErrWebSocketCloseSent = errors.New("websocket: close sent")
+
+// WebSocketCloseError 连接以关闭帧结束时 ReadMessage 返回的错误
+type WebSocketCloseError struct {
+	Code int
+	Text string
+}
+
+func (e *WebSocketCloseError) Error() string {
+	if e.Text == "" {
+		return "websocket: close " + strconv.Itoa(e.Code)
+	}
+	return "websocket: close " + strconv.Itoa(e.Code) + ": " + e.Text
+}
+
+// WebSocketOptions Context.UpgradeWebSocket 的选项，通过 Engine.WebSocket 设置，为零值的字段使用默认值
+type WebSocketOptions struct {
+	// ReadLimit 单条消息的最大字节数（解压后），超过时以 1009 关闭连接，默认 32MB
+	ReadLimit int64
+	// WriteTimeout 每次写入的超时时间，默认 10 秒
+	WriteTimeout time.Duration
+	// PingInterval 发送 ping 的间隔，为 0 时不发送
+	// 开启后 ReadMessage 在两个间隔内没有收到任何帧时返回超时错误
+	PingInterval time.Duration
+	// Subprotocols 支持的子协议，选择客户端请求中第一个支持的子协议
+	Subprotocols []string
+	// CheckOrigin 校验 Origin 请求头，为 nil 时要求 Origin 的 host 与请求的 Host 相同
+	CheckOrigin func(r *http.Request) bool
+	// EnableCompression 客户端支持时使用 permessage-deflate 扩展压缩消息，不保留上下文
+	EnableCompression bool
+}
+
+// WebSocketConn 一个 WebSocket 连接
+// ReadMessage 同一时间只能在一个 goroutine 中调用，写方法可以在多个 goroutine 中并发调用
+type WebSocketConn struct {
+	conn         net.Conn
+	br           *bufio.Reader
+	server       bool
+	subprotocol  string
+	compress     bool
+	readLimit    int64
+	writeTimeout time.Duration
+	pingInterval time.Duration
+	readErr      error
+
+	writeMu   sync.Mutex
+	closeSent bool // 由 writeMu 保护
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func newWebSocketConn(conn net.Conn, br *bufio.Reader, server bool, opts WebSocketOptions) *WebSocketConn {
+	if opts.ReadLimit <= 0 {
+		opts.ReadLimit = defaultWebSocketReadLimit
+	}
+	if opts.WriteTimeout <= 0 {
+		opts.WriteTimeout = defaultWebSocketTimeout
+	}
+	if br == nil {
+		br = bufio.NewReader(conn)
+	}
+	ws := &WebSocketConn{
+		conn:         conn,
+		br:           br,
+		server:       server,
+		readLimit:    opts.ReadLimit,
+		writeTimeout: opts.WriteTimeout,
+		pingInterval: opts.PingInterval,
+		closed:       make(chan struct{}),
+	}
+	if ws.pingInterval > 0 {
+		go ws.keepAlive()
+	}
+	return ws
+}
+
+// Subprotocol 握手时协商的子协议
+func (ws *WebSocketConn) Subprotocol() string {
+	return ws.subprotocol
+}
+
+// Compressed 是否协商了 permessage-deflate 扩展
+func (ws *WebSocketConn) Compressed() bool {
+	return ws.compress
+}
+
+// RemoteAddr 对端地址
+func (ws *WebSocketConn) RemoteAddr() net.Addr {
+	return ws.conn.RemoteAddr()
+}
+
+// Done 返回的 channel 在底层连接关闭时关闭
+func (ws *WebSocketConn) Done() <-chan struct{} {
+	return ws.closed
+}
+
+// ReadMessage 读取一条完整的消息，分片的消息会被合并，ping、pong 和关闭帧在内部处理
+// 对端关闭连接时返回 *WebSocketCloseError，返回错误后连接不再可读
+func (ws *WebSocketConn) ReadMessage() (messageType int, data []byte, err error) {
+	if ws.readErr != nil {
+		return 0, nil, ws.readErr
+	}
+	defer func() {
+		if err != nil {
+			ws.readErr = err
+		}
+	}()
+
+	compressed := false
+	for {
+		if ws.pingInterval > 0 {
+			_ = ws.conn.SetReadDeadline(time.Now().Add(2 * ws.pingInterval))
+		}
+		f, err := ws.readFrame()
+		if err != nil {
+			return 0, nil, ws.fail(err)
+		}
+		switch f.opcode {
+		case WebSocketCloseMessage, WebSocketPingMessage, WebSocketPongMessage:
+			if err := ws.handleControl(f); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case 0:
+			if messageType == 0 || f.rsv1 {
+				return 0, nil, ws.fail(protocolError("unexpected continuation frame"))
+			}
+		case WebSocketTextMessage, WebSocketBinaryMessage:
+			if messageType != 0 {
+				return 0, nil, ws.fail(protocolError("expected continuation frame"))
+			}
+			if f.rsv1 && !ws.compress {
+				return 0, nil, ws.fail(protocolError("unexpected rsv1 bit"))
+			}
+			messageType, compressed = f.opcode, f.rsv1
+		default:
+			return 0, nil, ws.fail(protocolError("unknown opcode " + strconv.Itoa(f.opcode)))
+		}
+		if int64(len(data)+len(f.payload)) > ws.readLimit {
+			return 0, nil, ws.fail(&WebSocketCloseError{Code: WebSocketCloseMessageTooBig, Text: "message too big"})
+		}
+		data = append(data, f.payload...)
+		if f.fin {
+			break
+		}
+	}
+
+	if compressed {
+		if data, err = inflateMessage(data, ws.readLimit); err != nil {
+			return 0, nil, ws.fail(err)
+		}
+	}
+	if messageType == WebSocketTextMessage && !utf8.Valid(data) {
+		return 0, nil, ws.fail(&WebSocketCloseError{Code: WebSocketCloseInvalidPayloadData, Text: "invalid utf8"})
+	}
+	return messageType, data, nil
+}
+
+// WriteMessage 写入一条消息，messageType 为控制帧类型时等同于 WriteControl
+func (ws *WebSocketConn) WriteMessage(messageType int, data []byte) error {
+	switch messageType {
+	case WebSocketTextMessage, WebSocketBinaryMessage:
+	case WebSocketCloseMessage, WebSocketPingMessage, WebSocketPongMessage:
+		return ws.WriteControl(messageType, data)
+	default:
+		return errors.New("websocket: unknown message type " + strconv.Itoa(messageType))
+	}
+	if !ws.compress || len(data) == 0 {
+		return ws.writeFrame(messageType, false, data)
+	}
+	compressed, err := deflateMessage(data)
+	if err != nil {
+		return err
+	}
+	return ws.writeFrame(messageType, true, compressed)
+}
+
+// WriteControl 写入控制帧，data 不能超过 125 字节
+func (ws *WebSocketConn) WriteControl(messageType int, data []byte) error {
+	switch messageType {
+	case WebSocketCloseMessage, WebSocketPingMessage, WebSocketPongMessage:
+	default:
+		return errors.New("websocket: not a control message " + strconv.Itoa(messageType))
+	}
+	if len(data) > 125 {
+		return errors.New("websocket: control frame too long")
+	}
+	return ws.writeFrame(messageType, false, data)
+}
+
+// Ping 发送 ping 帧，对端回应的 pong 帧由 ReadMessage 处理
+func (ws *WebSocketConn) Ping(data []byte) error {
+	return ws.WriteControl(WebSocketPingMessage, data)
+}
+
+// Close 以 1000 状态码关闭连接，见 CloseWithReason
+func (ws *WebSocketConn) Close() error {
+	return ws.CloseWithReason(WebSocketCloseNormalClosure, "")
+}
+
+// CloseWithReason 发送关闭帧开始关闭握手
+// 对端回应的关闭帧由 ReadMessage 读取后关闭底层连接，对端 5 秒内没有回应时直接关闭
+func (ws *WebSocketConn) CloseWithReason(code int, reason string) error {
+	err := ws.writeClose(code, reason)
+	if errors.Is(err, ErrWebSocketCloseSent) {
+		return nil
+	}
+	if err != nil {
+		ws.closeConn()
+		return err
+	}
+	time.AfterFunc(websocketCloseTimeout, ws.closeConn)
+	return nil
+}
+
+func (ws *WebSocketConn) writeClose(code int, reason string) error {
+	var payload []byte
+	if code != WebSocketCloseNoStatusReceived {
+		payload = make([]byte, 2, 2+len(reason))
+		binary.BigEndian.PutUint16(payload, uint16(code))
+		payload = append(payload, reason...)
+		if len(payload) > 125 {
+			payload = payload[:125]
+		}
+	}
+	return ws.writeFrame(WebSocketCloseMessage, false, payload)
+}
+
+func (ws *WebSocketConn) closeConn() {
+	ws.closeOnce.Do(func() {
+		close(ws.closed)
+		_ = ws.conn.Close()
+	})
+}
+
+// keepAlive 定时发送 ping，直到连接关闭或者写入失败
+func (ws *WebSocketConn) keepAlive() {
+	ticker := time.NewTicker(ws.pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ws.closed:
+			return
+		case <-ticker.C:
+			if err := ws.Ping(nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// handleControl 处理控制帧，收到关闭帧时回应并关闭底层连接
+func (ws *WebSocketConn) handleControl(f websocketFrame) error {
+	switch f.opcode {
+	case WebSocketPingMessage:
+		if err := ws.WriteControl(WebSocketPongMessage, f.payload); err != nil && !errors.Is(err, ErrWebSocketCloseSent) {
+			return ws.fail(err)
+		}
+	case WebSocketCloseMessage:
+		closeErr := &WebSocketCloseError{Code: WebSocketCloseNoStatusReceived}
+		switch {
+		case len(f.payload) == 1:
+			return ws.fail(protocolError("invalid close payload"))
+		case len(f.payload) >= 2:
+			closeErr.Code = int(binary.BigEndian.Uint16(f.payload))
+			closeErr.Text = string(f.payload[2:])
+		}
+		_ = ws.writeClose(closeErr.Code, "")
+		ws.closeConn()
+		return closeErr
+	}
+	return nil
+}
+
+// fail 读取出错时结束连接，协议错误先发送对应状态码的关闭帧
+func (ws *WebSocketConn) fail(err error) error {
+	var closeErr *WebSocketCloseError
+	if errors.As(err, &closeErr) {
+		_ = ws.writeClose(closeErr.Code, closeErr.Text)
+	}
+	ws.closeConn()
+	return err
+}
+
+func protocolError(text string) error {
+	return &WebSocketCloseError{Code: WebSocketCloseProtocolError, Text: text}
+}
+
+type websocketFrame struct {
+	fin     bool
+	rsv1    bool
+	opcode  int
+	payload []byte
+}
+
+func (ws *WebSocketConn) readFrame() (websocketFrame, error) {
+	var f websocketFrame
+	var header [8]byte
+	if _, err := io.ReadFull(ws.br, header[:2]); err != nil {
+		return f, err
+	}
+	f.fin = header[0]&0x80 != 0
+	f.rsv1 = header[0]&0x40 != 0
+	f.opcode = int(header[0] & 0x0f)
+	if header[0]&0x30 != 0 {
+		return f, protocolError("unexpected rsv bits")
+	}
+	// 客户端发送的帧必须掩码，服务端发送的帧不能掩码
+	if masked := header[1]&0x80 != 0; masked != ws.server {
+		return f, protocolError("bad mask bit")
+	}
+
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		if _, err := io.ReadFull(ws.br, header[:2]); err != nil {
+			return f, err
+		}
+		length = uint64(binary.BigEndian.Uint16(header[:2]))
+	case 127:
+		if _, err := io.ReadFull(ws.br, header[:8]); err != nil {
+			return f, err
+		}
+		length = binary.BigEndian.Uint64(header[:8])
+	}
+	if f.opcode >= WebSocketCloseMessage && (!f.fin || length > 125) {
+		return f, protocolError("invalid control frame")
+	}
+	if length > uint64(ws.readLimit) {
+		return f, &WebSocketCloseError{Code: WebSocketCloseMessageTooBig, Text: "message too big"}
+	}
+
+	var key [4]byte
+	if ws.server {
+		if _, err := io.ReadFull(ws.br, key[:]); err != nil {
+			return f, err
+		}
+	}
+	f.payload = make([]byte, length)
+	if _, err := io.ReadFull(ws.br, f.payload); err != nil {
+		return f, err
+	}
+	if ws.server {
+		maskBytes(key, f.payload)
+	}
+	return f, nil
+}
+
+func (ws *WebSocketConn) writeFrame(opcode int, rsv1 bool, payload []byte) error {
+	header := make([]byte, 2, 14)
+	header[0] = 0x80 | byte(opcode)
+	if rsv1 {
+		header[0] |= 0x40
+	}
+	switch n := len(payload); {
+	case n <= 125:
+		header[1] = byte(n)
+	case n <= 0xffff:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+	if !ws.server {
+		var key [4]byte
+		if _, err := rand.Read(key[:]); err != nil {
+			return err
+		}
+		header[1] |= 0x80
+		header = append(header, key[:]...)
+		payload = append([]byte(nil), payload...)
+		maskBytes(key, payload)
+	}
+
+	ws.writeMu.Lock()
+	defer ws.writeMu.Unlock()
+	if ws.closeSent {
+		return ErrWebSocketCloseSent
+	}
+	if opcode == WebSocketCloseMessage {
+		ws.closeSent = true
+	}
+	_ = ws.conn.SetWriteDeadline(time.Now().Add(ws.writeTimeout))
+	buffers := net.Buffers{header}
+	if len(payload) > 0 {
+		buffers = append(buffers, payload)
+	}
+	_, err := buffers.WriteTo(ws.conn)
+	return err
+}
+
+func maskBytes(key [4]byte, b []byte) {
+	for i := range b {
+		b[i] ^= key[i&3]
+	}
+}
+
+var flateWriterPool = sync.Pool{New: func() interface{} {
+	w, _ := flate.NewWriter(nil, flate.BestSpeed)
+	return w
+}}
+
+// deflateMessage 压缩一条消息并去掉结尾的 0x00 0x00 0xff 0xff，见 RFC 7692 7.2.1
+func deflateMessage(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := flateWriterPool.Get().(*flate.Writer)
+	defer flateWriterPool.Put(w)
+	w.Reset(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte{0x00, 0x00, 0xff, 0xff}), nil
+}
+
+// inflateMessage 补上同步标记和一个结束块后解压，解压后超过 limit 时返回 1009
+func inflateMessage(data []byte, limit int64) ([]byte, error) {
+	r := flate.NewReader(io.MultiReader(bytes.NewReader(data), bytes.NewReader([]byte{0x00, 0x00, 0xff, 0xff, 0x01, 0x00, 0x00, 0xff, 0xff})))
+	defer r.Close()
+	out, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, &WebSocketCloseError{Code: WebSocketCloseInvalidPayloadData, Text: "invalid compressed data"}
+	}
+	if int64(len(out)) > limit {
+		return nil, &WebSocketCloseError{Code: WebSocketCloseMessageTooBig, Text: "message too big"}
+	}
+	return out, nil
+}
+
+// UpgradeWebSocket 将当前请求升级为 WebSocket 连接，选项为 Engine.WebSocket
+// 握手失败时写入对应的错误响应并 Abort；成功后连接脱离 Context，处理函数返回后仍然可以使用
+// c.Writer.Header() 中已经设置的响应头（例如中间件设置的 Set-Cookie）会一并写入握手响应
+func (c *Context) UpgradeWebSocket() (*WebSocketConn, error) {
+	var opts WebSocketOptions
+	if c.engine != nil {
+		opts = c.engine.WebSocket
+	}
+	r := c.Request
+	if r.Method != http.MethodGet {
+		return nil, c.websocketError(http.StatusMethodNotAllowed, "request method is not GET")
+	}
+	if !headerHasToken(r.Header, "Connection", "upgrade") || !headerHasToken(r.Header, "Upgrade", "websocket") {
+		return nil, c.websocketError(http.StatusBadRequest, "not a websocket handshake")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		c.Header("Sec-WebSocket-Version", "13")
+		return nil, c.websocketError(http.StatusUpgradeRequired, "unsupported version")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		return nil, c.websocketError(http.StatusBadRequest, "invalid Sec-WebSocket-Key")
+	}
+	checkOrigin := opts.CheckOrigin
+	if checkOrigin == nil {
+		checkOrigin = sameOrigin
+	}
+	if !checkOrigin(r) {
+		return nil, c.websocketError(http.StatusForbidden, "origin not allowed")
+	}
+
+	var subprotocol string
+	for _, p := range headerTokens(r.Header, "Sec-WebSocket-Protocol") {
+		if containsString(opts.Subprotocols, p) {
+			subprotocol = p
+			break
+		}
+	}
+	compress := opts.EnableCompression && acceptDeflate(r.Header)
+
+	conn, brw, err := c.Writer.Hijack()
+	if err != nil {
+		return nil, c.websocketError(http.StatusInternalServerError, err.Error())
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: ")
+	buf.WriteString(websocketAccept(key))
+	buf.WriteString("\r\n")
+	if subprotocol != "" {
+		buf.WriteString("Sec-WebSocket-Protocol: " + subprotocol + "\r\n")
+	}
+	if compress {
+		buf.WriteString("Sec-WebSocket-Extensions: " + websocketDeflateReply + "\r\n")
+	}
+	header := c.Writer.Header().Clone()
+	for _, k := range []string{"Upgrade", "Connection", "Sec-Websocket-Accept", "Sec-Websocket-Protocol", "Sec-Websocket-Extensions", "Content-Type"} {
+		header.Del(k)
+	}
+	_ = header.Write(&buf)
+	buf.WriteString("\r\n")
+
+	// 清除 http.Server 设置的超时
+	_ = conn.SetDeadline(time.Time{})
+	_ = conn.SetWriteDeadline(time.Now().Add(defaultWebSocketTimeout))
+	if _, err = conn.Write(buf.Bytes()); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	_ = conn.SetWriteDeadline(time.Time{})
+
+	ws := newWebSocketConn(conn, brw.Reader, true, opts)
+	ws.subprotocol, ws.compress = subprotocol, compress
+	return ws, nil
+}
+
+// websocketError 写入握手失败的响应，开启 Engine.ProblemDetails 时使用 problem+json 格式
+func (c *Context) websocketError(status int, reason string) error {
+	err := errors.New("websocket: " + reason)
+	if c.problemDetails() {
+		c.AbortWithProblem(NewProblem(status).WithDetail(err.Error()))
+	} else {
+		c.Abort()
+		c.String(status, err.Error())
+	}
+	return err
+}
+
+// sameOrigin 没有 Origin 请求头，或者 Origin 的 host 与请求的 Host 相同
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// websocketAccept 根据 Sec-WebSocket-Key 计算 Sec-WebSocket-Accept
+func websocketAccept(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// headerTokens 返回请求头中逗号分隔的所有值
+func headerTokens(header http.Header, name string) []string {
+	var tokens []string
+	for _, v := range header.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				tokens = append(tokens, t)
+			}
+		}
+	}
+	return tokens
+}
+
+func headerHasToken(header http.Header, name, token string) bool {
+	for _, t := range headerTokens(header, name) {
+		if strings.EqualFold(t, token) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptDeflate 客户端是否提供了可以接受的 permessage-deflate 参数
+// 压缩使用固定的 32K 窗口，所以不接受要求更小 server_max_window_bits 的提议
+func acceptDeflate(header http.Header) bool {
+	for _, offer := range headerTokens(header, "Sec-WebSocket-Extensions") {
+		params := strings.Split(offer, ";")
+		if strings.TrimSpace(params[0]) != websocketDeflate {
+			continue
+		}
+		ok := true
+		for _, p := range params[1:] {
+			name, value, _ := strings.Cut(strings.TrimSpace(p), "=")
+			if name == "server_max_window_bits" && strings.Trim(value, `"`) != "15" {
+				ok = false
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// WebSocketDialer WebSocket 客户端，可以在测试中连接 httptest.Server 启动的 Engine
+type WebSocketDialer struct {
+	// NetDialContext 建立底层连接，为 nil 时使用 net.Dialer
+	NetDialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+	// TLSClientConfig wss 连接的 TLS 配置
+	TLSClientConfig *tls.Config
+	// HandshakeTimeout 握手的超时时间，默认 10 秒
+	HandshakeTimeout time.Duration
+	// Subprotocols 请求的子协议
+	Subprotocols []string
+	// EnableCompression 请求 permessage-deflate 扩展
+	EnableCompression bool
+	// ReadLimit 和 WriteTimeout 与 WebSocketOptions 相同
+	ReadLimit    int64
+	WriteTimeout time.Duration
+}
+
+// DefaultWebSocketDialer 默认的 WebSocket 客户端
+var DefaultWebSocketDialer = &WebSocketDialer{}
+
+// Dial 连接 ws:// 或者 wss:// 地址，握手失败时返回服务端的响应
+func (d *WebSocketDialer) Dial(ctx context.Context, rawURL string, header http.Header) (*WebSocketConn, *http.Response, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch u.Scheme {
+	case "ws":
+		u.Scheme = "http"
+	case "wss":
+		u.Scheme = "https"
+	default:
+		return nil, nil, errors.New("websocket: bad scheme " + u.Scheme)
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		port := "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+		addr = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	timeout := d.HandshakeTimeout
+	if timeout <= 0 {
+		timeout = defaultWebSocketTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	dial := d.NetDialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	conn, err := dial(ctx, "tcp", addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	ws, resp, err := d.handshake(ctx, conn, u, header)
+	if err != nil {
+		_ = conn.Close()
+		return nil, resp, err
+	}
+	return ws, resp, nil
+}
+
+func (d *WebSocketDialer) handshake(ctx context.Context, conn net.Conn, u *url.URL, header http.Header) (*WebSocketConn, *http.Response, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if u.Scheme == "https" {
+		cfg := d.TLSClientConfig.Clone()
+		if cfg == nil {
+			cfg = &tls.Config{}
+		}
+		if cfg.ServerName == "" {
+			cfg.ServerName = u.Hostname()
+		}
+		tlsConn := tls.Client(conn, cfg)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return nil, nil, err
+		}
+		conn = tlsConn
+	}
+
+	var nonce [16]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce[:])
+	req := &http.Request{Method: http.MethodGet, URL: u, Host: u.Host, Header: header.Clone()}
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if len(d.Subprotocols) > 0 {
+		req.Header.Set("Sec-WebSocket-Protocol", strings.Join(d.Subprotocols, ", "))
+	}
+	if d.EnableCompression {
+		req.Header.Set("Sec-WebSocket-Extensions", websocketDeflateReply)
+	}
+	if err := req.Write(conn); err != nil {
+		return nil, nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols ||
+		!headerHasToken(resp.Header, "Upgrade", "websocket") ||
+		!headerHasToken(resp.Header, "Connection", "upgrade") ||
+		resp.Header.Get("Sec-WebSocket-Accept") != websocketAccept(key) {
+		return nil, resp, fmt.Errorf("websocket: bad handshake: %s", resp.Status)
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	ws := newWebSocketConn(conn, br, false, WebSocketOptions{ReadLimit: d.ReadLimit, WriteTimeout: d.WriteTimeout})
+	ws.subprotocol = resp.Header.Get("Sec-WebSocket-Protocol")
+	for _, ext := range headerTokens(resp.Header, "Sec-WebSocket-Extensions") {
+		if strings.HasPrefix(ext, websocketDeflate) {
+			ws.compress = d.EnableCompression
+		}
+	}
+	return ws, resp, nil
+}
@@ -0,0 +1,196 @@
+package gin
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newWebSocketServer 启动一个回显消息的 Engine，返回 ws:// 地址
+func newWebSocketServer(t *testing.T, opts WebSocketOptions) (string, chan error) {
+	done := make(chan error, 1)
+	router := New()
+	router.WebSocket = opts
+	router.Use(func(c *Context) {
+		c.Header("X-Request-Id", "42")
+	})
+	router.GET("/ws", func(c *Context) {
+		conn, err := c.UpgradeWebSocket()
+		if err != nil {
+			return
+		}
+		go func() {
+			for {
+				typ, data, err := conn.ReadMessage()
+				if err != nil {
+					done <- err
+					return
+				}
+				if err := conn.WriteMessage(typ, data); err != nil {
+					done <- err
+					return
+				}
+			}
+		}()
+	})
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http") + "/ws", done
+}
+
+func TestWebSocketEcho(t *testing.T) {
+	url, done := newWebSocketServer(t, WebSocketOptions{Subprotocols: []string{"chat"}, EnableCompression: true})
+	dialer := &WebSocketDialer{Subprotocols: []string{"v2", "chat"}, EnableCompression: true}
+	conn, resp, err := dialer.Dial(context.Background(), url, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "42", resp.Header.Get("X-Request-Id"))
+	assert.Equal(t, "chat", conn.Subprotocol())
+	assert.True(t, conn.Compressed())
+
+	long := strings.Repeat("httpgo ", 20000)
+	for _, msg := range []string{"hello", "", long} {
+		assert.NoError(t, conn.WriteMessage(WebSocketTextMessage, []byte(msg)))
+		typ, data, err := conn.ReadMessage()
+		assert.NoError(t, err)
+		assert.Equal(t, WebSocketTextMessage, typ)
+		assert.Equal(t, msg, string(data))
+	}
+	assert.NoError(t, conn.WriteMessage(WebSocketBinaryMessage, []byte{0, 1, 2}))
+	typ, data, err := conn.ReadMessage()
+	assert.NoError(t, err)
+	assert.Equal(t, WebSocketBinaryMessage, typ)
+	assert.Equal(t, []byte{0, 1, 2}, data)
+
+	// ping 由对端自动回应 pong，ReadMessage 跳过控制帧
+	assert.NoError(t, conn.Ping([]byte("p")))
+	assert.NoError(t, conn.WriteMessage(WebSocketTextMessage, []byte("after ping")))
+	_, data, err = conn.ReadMessage()
+	assert.NoError(t, err)
+	assert.Equal(t, "after ping", string(data))
+
+	// 关闭握手：服务端收到关闭帧后回应，客户端读到回应
+	assert.NoError(t, conn.CloseWithReason(WebSocketCloseGoingAway, "bye"))
+	var closeErr *WebSocketCloseError
+	assert.ErrorAs(t, <-done, &closeErr)
+	assert.Equal(t, WebSocketCloseGoingAway, closeErr.Code)
+	assert.Equal(t, "bye", closeErr.Text)
+	_, _, err = conn.ReadMessage()
+	assert.ErrorAs(t, err, &closeErr)
+	assert.Equal(t, WebSocketCloseGoingAway, closeErr.Code)
+	assert.ErrorIs(t, conn.WriteMessage(WebSocketTextMessage, []byte("late")), ErrWebSocketCloseSent)
+	select {
+	case <-conn.Done():
+	case <-time.After(time.Second):
+		t.Fatal("connection should be closed after the close handshake")
+	}
+}
+
+func TestWebSocketReadLimit(t *testing.T) {
+	url, done := newWebSocketServer(t, WebSocketOptions{ReadLimit: 8})
+	conn, _, err := DefaultWebSocketDialer.Dial(context.Background(), url, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, conn.WriteMessage(WebSocketTextMessage, []byte("too long message")))
+
+	var closeErr *WebSocketCloseError
+	assert.ErrorAs(t, <-done, &closeErr)
+	_, _, err = conn.ReadMessage()
+	assert.ErrorAs(t, err, &closeErr)
+	assert.Equal(t, WebSocketCloseMessageTooBig, closeErr.Code)
+}
+
+func TestWebSocketPing(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	ws := newWebSocketConn(server, nil, true, WebSocketOptions{PingInterval: 10 * time.Millisecond})
+	defer ws.closeConn()
+
+	// 服务端定时发送 ping
+	frame := make([]byte, 2)
+	_, err := io.ReadFull(client, frame)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x89, 0x00}, frame)
+
+	// 两个间隔内没有收到任何帧时读超时
+	_, _, err = ws.ReadMessage()
+	var netErr net.Error
+	assert.ErrorAs(t, err, &netErr)
+	assert.True(t, netErr.Timeout())
+}
+
+func TestWebSocketHandshakeErrors(t *testing.T) {
+	router := New()
+	router.GET("/ws", func(c *Context) {
+		_, _ = c.UpgradeWebSocket()
+	})
+	tests := []struct {
+		name   string
+		header map[string]string
+		status int
+	}{
+		{"plain request", nil, http.StatusBadRequest},
+		{"version", map[string]string{"Sec-WebSocket-Version": "8"}, http.StatusUpgradeRequired},
+		{"key", map[string]string{"Sec-WebSocket-Key": "short"}, http.StatusBadRequest},
+		{"origin", map[string]string{"Origin": "http://evil.example"}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+			if tt.header != nil {
+				req.Header.Set("Connection", "Upgrade")
+				req.Header.Set("Upgrade", "websocket")
+				req.Header.Set("Sec-WebSocket-Version", "13")
+				req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+				for k, v := range tt.header {
+					req.Header.Set(k, v)
+				}
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Code)
+			assert.True(t, strings.HasPrefix(w.Body.String(), "websocket: "))
+		})
+	}
+
+	_, resp, err := DefaultWebSocketDialer.Dial(context.Background(), "ws://127.0.0.1:1/ws", nil)
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+}
+
+func TestWebSocketAccept(t *testing.T) {
+	// RFC 6455 1.3 中的示例
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", websocketAccept("dGhlIHNhbXBsZSBub25jZQ=="))
+	assert.True(t, acceptDeflate(http.Header{"Sec-Websocket-Extensions": {"foo, permessage-deflate; client_max_window_bits"}}))
+	assert.False(t, acceptDeflate(http.Header{"Sec-Websocket-Extensions": {"permessage-deflate; server_max_window_bits=10"}}))
+}
+
+func TestWebSocketFragmentedMessage(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	ws := newWebSocketConn(server, nil, true, WebSocketOptions{})
+
+	go func() {
+		// 掩码为 0 的分片消息，中间插入一个 ping
+		_, _ = client.Write([]byte{0x01, 0x83, 0, 0, 0, 0, 'h', 'e', 'l'})
+		_, _ = client.Write([]byte{0x89, 0x80, 0, 0, 0, 0})
+		_, _ = client.Write([]byte{0x80, 0x82, 0, 0, 0, 0, 'l', 'o'})
+	}()
+	pong := make(chan []byte, 1)
+	go func() {
+		b := make([]byte, 2)
+		_, _ = io.ReadFull(client, b)
+		pong <- b
+	}()
+
+	typ, data, err := ws.ReadMessage()
+	assert.NoError(t, err)
+	assert.Equal(t, WebSocketTextMessage, typ)
+	assert.Equal(t, "hello", string(data))
+	assert.Equal(t, []byte{0x8a, 0x00}, <-pong)
+}
@@ -116,8 +116,15 @@ func CustomRecoveryWithWriter(out io.Writer, handle RecoveryFunc) HandlerFunc {
 	}
 }
 
-func defaultHandleRecovery(c *Context, _ any) {
-	c.AbortWithStatus(http.StatusInternalServerError)
+// defaultHandleRecovery 响应还没有写入时，使用 Fail 的格式（开启了 ProblemDetails 时为 Problem）输出 500 错误，
+// panic 的值会被记录到 Context.Errors 中
+// 使用 CustomRecovery 时可以在 handle 中调用 c.Fail 或者 c.AbortWithProblem 输出同样格式的响应
+func defaultHandleRecovery(c *Context, err any) {
+	if c.Writer.Written() {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	c.Fail(ErrInternal.Wrap(fmt.Errorf("panic: %v", err)))
 }
 
 // stack returns a nicely formatted stack frame, skipping skip frames.
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"html/template"
 	"net/http"
+	"reflect"
 
 	"github.com/gothms/httpgo/framework/gin/internal/bytesconv"
 	"github.com/gothms/httpgo/framework/gin/internal/json"
@@ -46,10 +47,23 @@ type PureJSON struct {
 	Data any
 }
 
+// ProblemJSON contains the given problem details object (RFC 7807).
+type ProblemJSON struct {
+	Data any
+}
+
+// StreamJSON contains the given interface object.
+// Data 为切片、数组或者 channel 时按元素逐个编码并写入响应，同一时间只在内存中保留一个元素的 JSON，
+// channel 中的每个元素写入后会 Flush，直到 channel 关闭；其他类型的值会整体编码后写入
+type StreamJSON struct {
+	Data any
+}
+
 var (
 	jsonContentType      = []string{"application/json; charset=utf-8"}
 	jsonpContentType     = []string{"application/javascript; charset=utf-8"}
 	jsonASCIIContentType = []string{"application/json"}
+	problemContentType   = []string{"application/problem+json"}
 )
 
 // Render (JSON) writes data with custom ContentType.
@@ -188,3 +202,83 @@ func (r PureJSON) Render(w http.ResponseWriter) error {
 func (r PureJSON) WriteContentType(w http.ResponseWriter) {
 	writeContentType(w, jsonContentType)
 }
+
+// Render (StreamJSON) encodes the given interface object to the response element by element.
+// 编码某个元素失败时返回错误，此时之前的元素已经写入响应
+func (r StreamJSON) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	v := reflect.ValueOf(r.Data)
+	switch v.Kind() {
+	case reflect.Slice:
+		// []byte 编码为 base64 字符串
+		if v.Type().Elem().Kind() == reflect.Uint8 || v.IsNil() {
+			break
+		}
+		fallthrough
+	case reflect.Array:
+		return writeJSONArray(w, func(i int) (reflect.Value, bool) {
+			if i >= v.Len() {
+				return reflect.Value{}, false
+			}
+			return v.Index(i), true
+		}, false)
+	case reflect.Chan:
+		if v.Type().ChanDir()&reflect.RecvDir == 0 {
+			break
+		}
+		return writeJSONArray(w, func(int) (reflect.Value, bool) {
+			return v.Recv()
+		}, true)
+	}
+	return json.NewEncoder(w).Encode(r.Data)
+}
+
+// writeJSONArray 逐个编码 next 返回的元素，写成 JSON 数组，flush 为 true 时每个元素写入后 Flush
+func writeJSONArray(w http.ResponseWriter, next func(i int) (reflect.Value, bool), flush bool) error {
+	if _, err := w.Write([]byte{'['}); err != nil {
+		return err
+	}
+	flusher, _ := w.(http.Flusher)
+	for i := 0; ; i++ {
+		elem, ok := next(i)
+		if !ok {
+			break
+		}
+		jsonBytes, err := json.Marshal(elem.Interface())
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			jsonBytes = append([]byte{','}, jsonBytes...)
+		}
+		if _, err = w.Write(jsonBytes); err != nil {
+			return err
+		}
+		if flush && flusher != nil {
+			flusher.Flush()
+		}
+	}
+	_, err := w.Write([]byte("]\n"))
+	return err
+}
+
+// WriteContentType (StreamJSON) writes JSON ContentType.
+func (r StreamJSON) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, jsonContentType)
+}
+
+// Render (ProblemJSON) marshals the given problem details object and writes it with problem+json ContentType.
+func (r ProblemJSON) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	jsonBytes, err := json.Marshal(r.Data)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(jsonBytes)
+	return err
+}
+
+// WriteContentType (ProblemJSON) writes problem+json ContentType.
+func (r ProblemJSON) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, problemContentType)
+}
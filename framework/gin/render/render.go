@@ -29,6 +29,8 @@ var (
 	_ Render     = YAML{}
 	_ Render     = Reader{}
 	_ Render     = AsciiJSON{}
+	_ Render     = StreamJSON{}
+	_ Render     = ProblemJSON{}
 	_ Render     = ProtoBuf{}
 	_ Render     = TOML{}
 )
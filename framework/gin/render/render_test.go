@@ -24,6 +24,62 @@ import (
 // TODO unit tests
 // test errors
 
+func TestRenderStreamJSON(t *testing.T) {
+	w := httptest.NewRecorder()
+	data := map[string]any{
+		"foo":  "bar",
+		"html": "<b>",
+	}
+
+	err := (StreamJSON{data}).Render(w)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"foo\":\"bar\",\"html\":\"\\u003cb\\u003e\"}\n", w.Body.String())
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+
+	err = (StreamJSON{func() {}}).Render(httptest.NewRecorder())
+	assert.Error(t, err)
+
+	// 切片、数组和 channel 按元素逐个写入
+	w = httptest.NewRecorder()
+	assert.NoError(t, (StreamJSON{[]map[string]int{{"a": 1}, {"b": 2}}}).Render(w))
+	assert.Equal(t, "[{\"a\":1},{\"b\":2}]\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	assert.NoError(t, (StreamJSON{[0]int{}}).Render(w))
+	assert.Equal(t, "[]\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	assert.NoError(t, (StreamJSON{[]byte("ab")}).Render(w))
+	assert.Equal(t, "\"YWI=\"\n", w.Body.String())
+
+	ch := make(chan string)
+	go func() {
+		ch <- "x"
+		ch <- "y"
+		close(ch)
+	}()
+	w = httptest.NewRecorder()
+	assert.NoError(t, (StreamJSON{(<-chan string)(ch)}).Render(w))
+	assert.Equal(t, "[\"x\",\"y\"]\n", w.Body.String())
+	assert.True(t, w.Flushed)
+
+	// 编码失败时之前的元素已经写入
+	w = httptest.NewRecorder()
+	assert.Error(t, (StreamJSON{[]any{1, func() {}}}).Render(w))
+	assert.Equal(t, "[1", w.Body.String())
+}
+
+func TestRenderProblemJSON(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := (ProblemJSON{map[string]any{"status": 404}}).Render(w)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `{"status":404}`, w.Body.String())
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+}
+
 func TestRenderJSON(t *testing.T) {
 	w := httptest.NewRecorder()
 	data := map[string]any{
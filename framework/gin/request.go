@@ -8,6 +8,7 @@ import (
 //const defaultMultipartMemory = 32 << 20 // 32 MB
 
 // 代表请求包含的方法
+// 需要返回解析错误或者其他类型时，使用泛型的 QueryAs、ParamAs、FormAs、HeaderAs
 type IRequest interface {
 	// 请求地址url中带的参数
 	// 形如: foo.com?a=1&b=bar&c[]=bar
@@ -271,9 +272,10 @@ func (ctx *Context) DefaultFormStringSlice(key string, def []string) ([]string,
 	return def, false
 }
 
+// DefaultFormFile 与 FormFile 相同，需要限制大小和类型时使用 UploadFile
 func (ctx *Context) DefaultFormFile(key string) (*multipart.FileHeader, error) {
 	if ctx.Request.MultipartForm == nil {
-		if err := ctx.Request.ParseMultipartForm(defaultMultipartMemory); err != nil {
+		if err := ctx.Request.ParseMultipartForm(ctx.engine.MaxMultipartMemory); err != nil {
 			return nil, err
 		}
 	}
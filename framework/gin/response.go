@@ -1,12 +1,12 @@
 package gin
 
 import (
-	"encoding/json"
-	"encoding/xml"
-	"fmt"
 	"html/template"
 	"net/http"
 	"net/url"
+	"path/filepath"
+
+	"github.com/gothms/httpgo/framework/gin/render"
 )
 
 // IResponse代表返回方法
@@ -14,6 +14,12 @@ type IResponse interface {
 	// Json输出
 	IJson(obj interface{}) IResponse
 
+	// Json流式输出
+	IJsonStream(obj interface{}) IResponse
+
+	// 根据Accept请求头选择输出格式
+	IRespond(obj interface{}) IResponse
+
 	// Jsonp输出
 	IJsonp(obj interface{}) IResponse
 
@@ -40,93 +46,81 @@ type IResponse interface {
 
 	// 设置200状态
 	ISetOkStatus() IResponse
+
+	// 使用统一的格式输出成功的响应
+	Success(data interface{}) IResponse
+
+	// 使用统一的格式输出错误的响应
+	Fail(err error) IResponse
 }
 
 var _ IResponse = (*Context)(nil)
 
-func (ctx *Context) IJson(obj interface{}) IResponse {
-	byt, err := json.Marshal(obj)
-	if err != nil {
-		return ctx.ISetStatus(http.StatusInternalServerError)
+// iRender 使用 ISetStatus 设置的状态码（默认 200）渲染响应，和 Context.JSON 等方法一样使用 render 包
+// 渲染失败时错误会记录到 Context.Errors 中，此时还没有写入响应的话状态码改为 500
+func (ctx *Context) iRender(r render.Render) IResponse {
+	errs := len(ctx.Errors)
+	ctx.Render(ctx.Writer.Status(), r)
+	if len(ctx.Errors) > errs && !ctx.Writer.Written() {
+		ctx.Writer.WriteHeader(http.StatusInternalServerError)
+		ctx.Writer.WriteHeaderNow()
 	}
-	ctx.ISetHeader("Content-Type", "application/json")
-	ctx.Writer.Write(byt)
 	return ctx
 }
 
+// Json输出
+func (ctx *Context) IJson(obj interface{}) IResponse {
+	return ctx.iRender(render.JSON{Data: obj})
+}
+
+// Json流式输出，obj 为切片、数组或者 channel 时按元素逐个编码写入，不在内存中生成完整的 JSON
+func (ctx *Context) IJsonStream(obj interface{}) IResponse {
+	return ctx.iRender(render.StreamJSON{Data: obj})
+}
+
 // Jsonp输出
 func (ctx *Context) IJsonp(obj interface{}) IResponse {
-	// 获取请求参数callback
+	// 获取请求参数callback，输出时会进行转义，避免造成xss攻击
 	callbackFunc, _ := ctx.DefaultQueryString("callback", "callback_function")
-	ctx.ISetHeader("Content-Type", "application/javascript")
-	// 输出到前端页面的时候需要注意下进行字符过滤，否则有可能造成xss攻击
-	callback := template.JSEscapeString(callbackFunc)
-
-	// 输出函数名
-	_, err := ctx.Writer.Write([]byte(callback))
-	if err != nil {
-		return ctx
-	}
-	// 输出左括号
-	_, err = ctx.Writer.Write([]byte("("))
-	if err != nil {
-		return ctx
-	}
-	// 数据函数参数
-	ret, err := json.Marshal(obj)
-	if err != nil {
-		return ctx
-	}
-	_, err = ctx.Writer.Write(ret)
-	if err != nil {
-		return ctx
-	}
-	// 输出右括号
-	_, err = ctx.Writer.Write([]byte(")"))
-	if err != nil {
-		return ctx
-	}
-	return ctx
+	return ctx.iRender(render.JsonpJSON{Callback: callbackFunc, Data: obj})
 }
 
 // xml输出
 func (ctx *Context) IXml(obj interface{}) IResponse {
-	byt, err := xml.Marshal(obj)
-	if err != nil {
-		return ctx.ISetStatus(http.StatusInternalServerError)
-	}
-	ctx.ISetHeader("Content-Type", "application/html")
-	ctx.Writer.Write(byt)
-	return ctx
+	return ctx.iRender(render.XML{Data: obj})
 }
 
 // html输出
+// 通过 LoadHTMLGlob、LoadHTMLFiles 或者 Engine.HTMLRender 加载了模版时，file 为模版名，
+// 否则 file 为模版文件的路径，解析时使用 Engine 的 Delims 和 FuncMap
 func (ctx *Context) IHtml(file string, obj interface{}) IResponse {
-	// 读取模版文件，创建template实例
-	t, err := template.New("output").ParseFiles(file)
-	if err != nil {
-		return ctx
+	if ctx.engine.HTMLRender != nil {
+		return ctx.iRender(ctx.engine.HTMLRender.Instance(file, obj))
 	}
-	// 执行Execute方法将obj和模版进行结合
-	if err := t.Execute(ctx.Writer, obj); err != nil {
-		return ctx
+	name := filepath.Base(file)
+	t, err := template.New(name).
+		Delims(ctx.engine.delims.Left, ctx.engine.delims.Right).
+		Funcs(ctx.engine.FuncMap).
+		ParseFiles(file)
+	if err != nil {
+		_ = ctx.Error(err)
+		return ctx.ISetStatus(http.StatusInternalServerError)
 	}
-
-	ctx.ISetHeader("Content-Type", "application/html")
-	return ctx
+	return ctx.iRender(render.HTML{Template: t, Name: name, Data: obj})
 }
 
 // string
 func (ctx *Context) IText(format string, values ...interface{}) IResponse {
-	out := fmt.Sprintf(format, values...)
-	ctx.ISetHeader("Content-Type", "application/text")
-	ctx.Writer.Write([]byte(out))
-	return ctx
+	return ctx.iRender(render.String{Format: format, Data: values})
 }
 
 // 重定向
 func (ctx *Context) IRedirect(path string) IResponse {
-	http.Redirect(ctx.Writer, ctx.Request, path, http.StatusMovedPermanently)
+	ctx.Render(-1, render.Redirect{
+		Code:     http.StatusMovedPermanently,
+		Location: path,
+		Request:  ctx.Request,
+	})
 	return ctx
 }
 
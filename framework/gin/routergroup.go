@@ -46,6 +46,8 @@ type IRoutes interface {
 	OPTIONS(string, ...HandlerFunc) IRoutes
 	HEAD(string, ...HandlerFunc) IRoutes
 	Match([]string, string, ...HandlerFunc) IRoutes
+	Name(string) IRoutes
+	Doc(RouteDoc) IRoutes
 
 	StaticFile(string, string) IRoutes
 	StaticFileFS(string, string, http.FileSystem) IRoutes
@@ -61,7 +63,8 @@ type RouterGroup struct {
 	Handlers HandlersChain // 全局
 	basePath string        // 该 RouterGroup 所对应的路由前缀
 	engine   *Engine
-	root     bool // 该 RouterGroup 是否为根
+	root     bool        // 该 RouterGroup 是否为根
+	host     *hostRouter // 该 RouterGroup 所属的 host，nil 表示默认的路由树
 }
 
 var _ IRouter = (*RouterGroup)(nil)
@@ -81,6 +84,7 @@ func (group *RouterGroup) Group(relativePath string, handlers ...HandlerFunc) *R
 		Handlers: group.combineHandlers(handlers),
 		basePath: group.calculateAbsolutePath(relativePath),
 		engine:   group.engine,
+		host:     group.host,
 	}
 }
 
@@ -94,7 +98,8 @@ func (group *RouterGroup) BasePath() string {
 func (group *RouterGroup) handle(httpMethod, relativePath string, handlers HandlersChain) IRoutes {
 	absolutePath := group.calculateAbsolutePath(relativePath) // join group.basePath
 	handlers = group.combineHandlers(handlers)
-	group.engine.addRoute(httpMethod, absolutePath, handlers) // 添加路由
+	group.engine.addHostRoute(group.host, httpMethod, absolutePath, handlers) // 添加路由
+	group.engine.setLastRoutes([]routeKey{{host: group.hostPattern(), method: httpMethod, path: absolutePath}})
 	return group.returnObj()
 }
 
@@ -157,20 +162,12 @@ func (group *RouterGroup) HEAD(relativePath string, handlers ...HandlerFunc) IRo
 // GET, POST, PUT, PATCH, HEAD, OPTIONS, DELETE, CONNECT, TRACE.
 // 将HTTP methods所有方法都注册上去
 func (group *RouterGroup) Any(relativePath string, handlers ...HandlerFunc) IRoutes {
-	for _, method := range anyMethods {
-		group.handle(method, relativePath, handlers)
-	}
-
-	return group.returnObj()
+	return group.handleAll(anyMethods, relativePath, handlers)
 }
 
 // Match registers a route that matches the specified methods that you declared.
 func (group *RouterGroup) Match(methods []string, relativePath string, handlers ...HandlerFunc) IRoutes {
-	for _, method := range methods {
-		group.handle(method, relativePath, handlers)
-	}
-
-	return group.returnObj()
+	return group.handleAll(methods, relativePath, handlers)
 }
 
 // StaticFile registers a single route in order to serve a single file of the local filesystem.
@@ -196,9 +193,7 @@ func (group *RouterGroup) staticFileHandler(relativePath string, handler Handler
 	if strings.Contains(relativePath, ":") || strings.Contains(relativePath, "*") {
 		panic("URL parameters can not be used when serving a static file")
 	}
-	group.GET(relativePath, handler)
-	group.HEAD(relativePath, handler)
-	return group.returnObj()
+	return group.handleAll([]string{http.MethodGet, http.MethodHead}, relativePath, HandlersChain{handler})
 }
 
 // Static serves files from the given file system root.
@@ -226,9 +221,7 @@ func (group *RouterGroup) StaticFS(relativePath string, fs http.FileSystem) IRou
 	urlPattern := path.Join(relativePath, "/*filepath")
 
 	// Register GET and HEAD handlers
-	group.GET(urlPattern, handler)
-	group.HEAD(urlPattern, handler)
-	return group.returnObj()
+	return group.handleAll([]string{http.MethodGet, http.MethodHead}, urlPattern, HandlersChain{handler})
 }
 
 func (group *RouterGroup) createStaticHandler(relativePath string, fs http.FileSystem) HandlerFunc {
@@ -18,6 +18,7 @@ var (
 	strColon = []byte(":")
 	strStar  = []byte("*")
 	strSlash = []byte("/")
+	strBrace = []byte("{")
 )
 
 // Param is a single URL parameter, consisting of a key and a value.
@@ -98,6 +99,7 @@ func countParams(path string) uint16 {
 	s := bytesconv.StringToBytes(path)
 	n += uint16(bytes.Count(s, strColon)) // ':' bytealg.Count(s, sep[0])
 	n += uint16(bytes.Count(s, strStar))  // '*'
+	n += uint16(bytes.Count(s, strBrace)) // '{'：{id} 形式的参数，{id:int} 会被多计一次，只影响预分配容量
 	return n
 }
 
@@ -124,6 +126,8 @@ type node struct {
 	children  []*node       // child nodes, at most 1 :param style node at the end of the array
 	handlers  HandlersChain // 节点路径的handle
 	fullPath  string        // 全路径，可能为 ""，仅用于报错
+	// param 节点上的参数约束，例如 {id:int}，为 nil 表示不做约束
+	constraint *paramConstraint
 }
 
 // Increments priority of the given child and reorders if necessary
@@ -156,11 +160,14 @@ func (n *node) incrementChildPrio(pos int) int {
 // 不是并发安全的!
 func (n *node) addRoute(path string, handlers HandlersChain) {
 	fullPath := path
+	// {id:int} 转换为 :id，约束挂到对应的 param 节点上
+	path, constraints := parseParamConstraints(path)
+	treePath := path
 	n.priority++
 
 	// Empty tree
 	if len(n.path) == 0 && len(n.children) == 0 {
-		n.insertChild(path, fullPath, handlers)
+		n.insertChild(path, fullPath, constraints, handlers)
 		n.nType = root
 		return
 	}
@@ -185,6 +192,8 @@ walk:
 				handlers:  n.handlers,
 				priority:  n.priority - 1,
 				fullPath:  n.fullPath,
+
+				constraint: n.constraint,
 			}
 
 			n.children = []*node{&child} // 因为 child 仍然是所有以child为前缀的路径的公共前缀
@@ -193,7 +202,8 @@ walk:
 			n.path = path[:i] // 当前节点：原节点path的前半部分
 			n.handlers = nil
 			n.wildChild = false
-			n.fullPath = fullPath[:parentFullPathIndex+i]
+			n.fullPath = fullPathPrefix(fullPath, parentFullPathIndex+i)
+			n.constraint = nil
 		}
 
 		// Make new node a child of this node
@@ -231,6 +241,7 @@ walk:
 				n = child
 			} else if n.wildChild { // 同为参数节点 : 或 *
 				// inserting a wildcard node, need to check if it conflicts with the existing wildcard
+				parentFullPathIndex += len(n.path)
 				n = n.children[len(n.children)-1] // 参数节点为最后一个子节点
 				n.priority++
 
@@ -241,6 +252,14 @@ walk:
 					// Check for longer wildcard, e.g. :name and :names
 					//(len(n.path) >= len(path) || path[len(n.path)] == '/') { // 逻辑判断不严谨 TODO
 					(len(n.path) == len(path) || path[len(n.path)] == '/') {
+					// 同名参数的约束也必须相同，例如 {id:int} 和 {id:uuid} 冲突
+					if !sameConstraint(n.constraint, constraints[n.path[1:]]) {
+						panic("param '" + n.path[1:] +
+							"' in new path '" + fullPath +
+							"' conflicts with existing constraint '" + constraintPattern(n) +
+							"' in existing path '" + n.fullPath +
+							"'")
+					}
 					continue walk // 继续查找，并去掉公共前缀
 				} // 参数节点完全相同
 
@@ -249,7 +268,7 @@ walk:
 				if n.nType != catchAll { // 从冲突部分的开始到下一个'/'之前，为错误路径
 					pathSeg = strings.SplitN(pathSeg, "/", 2)[0]
 				}
-				prefix := fullPath[:strings.Index(fullPath, pathSeg)] + n.path // 树中包含冲突节点的前缀路径
+				prefix := treePath[:strings.Index(treePath, pathSeg)] + n.path // 树中包含冲突节点的前缀路径
 				panic("'" + pathSeg +
 					"' in new path '" + fullPath +
 					"' conflicts with existing wildcard '" + n.path +
@@ -257,7 +276,7 @@ walk:
 					"'") // 参数节点不同
 			}
 
-			n.insertChild(path, fullPath, handlers) // 直接插入新节点
+			n.insertChild(path, fullPath, constraints, handlers) // 直接插入新节点
 			return
 		}
 
@@ -301,7 +320,8 @@ func findWildcard(path string) (wildcard string, i int, valid bool) {
 
 // insertChild addRoute 和 insertChild 两个功能解耦得非常干净
 // addRoute 函数本身的代码只负责对公共前缀的查找和对路由中已有路径的节点进行修改，不会涉及到新路径节点的添加
-func (n *node) insertChild(path string, fullPath string, handlers HandlersChain) {
+// constraints 为参数名对应的约束，由 addRoute 解析路径得到
+func (n *node) insertChild(path string, fullPath string, constraints map[string]*paramConstraint, handlers HandlersChain) {
 	for {
 		// Find prefix until first wildcard
 		wildcard, i, valid := findWildcard(path)
@@ -332,9 +352,10 @@ func (n *node) insertChild(path string, fullPath string, handlers HandlersChain)
 			}
 
 			child := &node{
-				nType:    param,
-				path:     wildcard,
-				fullPath: fullPath,
+				nType:      param,
+				path:       wildcard,
+				fullPath:   fullPath,
+				constraint: constraints[wildcard[1:]],
 			}
 			n.addChild(child)
 			n.wildChild = true // : 前的节点
@@ -470,6 +491,8 @@ walk: // Outer loop for walking the tree
 									children:  n.children,
 									handlers:  n.handlers,
 									fullPath:  n.fullPath,
+
+									constraint: n.constraint,
 								},
 								paramsCount: globalParamsCount,
 							}
@@ -521,6 +544,32 @@ walk: // Outer loop for walking the tree
 						end++
 					}
 
+					val := path[:end]
+					if unescape {
+						if v, err := url.QueryUnescape(val); err == nil {
+							val = v
+						}
+					}
+
+					// 参数值不满足约束：回滚到最后一个有效的skippedNode，例如 /user/{id:int} 和 /user/me
+					// 没有可回滚的节点则视为未匹配，返回 404
+					if n.constraint != nil && !n.constraint.match(val) {
+						for length := len(*skippedNodes); length > 0; length-- {
+							skippedNode := (*skippedNodes)[length-1]
+							*skippedNodes = (*skippedNodes)[:length-1]
+							if strings.HasSuffix(skippedNode.path, path) {
+								path = skippedNode.path
+								n = skippedNode.node
+								if value.params != nil {
+									*value.params = (*value.params)[:skippedNode.paramsCount]
+								}
+								globalParamsCount = skippedNode.paramsCount
+								continue walk
+							}
+						}
+						return
+					}
+
 					// Save param value
 					if params != nil && cap(*params) > 0 {
 						if value.params == nil {
@@ -529,12 +578,6 @@ walk: // Outer loop for walking the tree
 						// Expand slice within preallocated capacity
 						i := len(*value.params)
 						*value.params = (*value.params)[:i+1] // 扩容。params 的值的设置：func (engine *Engine) addRoute
-						val := path[:end]
-						if unescape {
-							if v, err := url.QueryUnescape(val); err == nil {
-								val = v
-							}
-						}
 						(*value.params)[i] = Param{
 							Key:   n.path[1:], // 去掉 ':'
 							Value: val,
@@ -849,8 +892,8 @@ walk: // Outer loop for walking the tree
 			return nil
 		}
 
-		// : 或 *
-		n = n.children[0]
+		// : 或 *，通配符节点总是最后一个子节点
+		n = n.children[len(n.children)-1]
 		switch n.nType {
 		case param: // 命名捕获参数
 			// 相对 getValue 中的逻辑几乎一样
@@ -862,6 +905,11 @@ walk: // Outer loop for walking the tree
 				end++
 			}
 
+			// 参数值不满足约束
+			if n.constraint != nil && !n.constraint.match(path[:end]) {
+				return nil
+			}
+
 			// Add param value to case insensitive path
 			ciPath = append(ciPath, path[:end]...) // 记录路径
 
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+
+	"github.com/gothms/httpgo/framework/gin"
+)
+
+// FrontendProxy 将请求反向代理到本地的前端 dev server
+// 一般作为 NoRoute 的处理函数使用：已经注册的 API 路由依然由 gin 的 handler 处理，
+// 其余的页面、静态资源请求都转发给前端 dev server，前端修改代码后不需要重新编译到 dist 目录
+// WebSocket 的 Upgrade 请求（如 HMR 热更新）会直接透传
+func FrontendProxy(target string) (gin.HandlerFunc, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("frontend dev server address %q must be an absolute url", target)
+	}
+
+	proxy := httputil.NewSingleHostReverseProxy(u)
+	director := proxy.Director
+	proxy.Director = func(req *http.Request) {
+		director(req)
+		// dev server 一般会校验 Host，这里改写为 dev server 自身的地址
+		req.Host = u.Host
+	}
+	// 立即 flush，保证 HMR 这类长连接的事件流能实时到达浏览器
+	proxy.FlushInterval = -1
+	proxy.ErrorHandler = func(w http.ResponseWriter, req *http.Request, err error) {
+		w.WriteHeader(http.StatusBadGateway)
+		fmt.Fprintf(w, "frontend dev server %s unavailable: %v", u.Host, err)
+	}
+
+	return func(c *gin.Context) {
+		proxy.ServeHTTP(c.Writer, c.Request)
+		c.Abort()
+	}, nil
+}
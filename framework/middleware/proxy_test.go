@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gothms/httpgo/framework/gin"
+)
+
+func TestFrontendProxy(t *testing.T) {
+	var host string
+	frontend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = r.Host
+		io.WriteString(w, "dev server "+r.URL.RequestURI())
+	}))
+	defer frontend.Close()
+
+	proxy, err := FrontendProxy(frontend.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := gin.New()
+	r.NoRoute(proxy)
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/assets/app.js?v=1", nil)
+	req.Host = "example.com"
+	code, body := do(t, req)
+	if code != http.StatusOK || body != "dev server /assets/app.js?v=1" {
+		t.Fatalf("unexpected response %d %q", code, body)
+	}
+	u, _ := url.Parse(frontend.URL)
+	if host != u.Host {
+		t.Fatalf("Host should be rewritten to %s, got %s", u.Host, host)
+	}
+
+	frontend.Close()
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"/", nil)
+	if code, _ := do(t, req); code != http.StatusBadGateway {
+		t.Fatalf("expected 502 when dev server is down, got %d", code)
+	}
+
+	if _, err := FrontendProxy("127.0.0.1:8071"); err == nil {
+		t.Fatal("relative address should be rejected")
+	}
+}
+
+func do(t *testing.T, req *http.Request) (int, string) {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
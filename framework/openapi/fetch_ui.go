@@ -0,0 +1,85 @@
+//go:build ignore
+// +build ignore
+
+// 从 npm 下载 swagger-ui-dist，将页面需要的脚本、样式和 LICENSE 放到 ui/assets 目录下，通过 go generate 执行：
+//
+//	go generate ./framework/openapi
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// swaggerUIVersion 使用的 swagger-ui-dist 版本，升级时修改这里并重新执行 go generate
+const swaggerUIVersion = "5.18.2"
+
+// assetsFolder 存放 swagger-ui-dist 文件的目录，相对于 framework/openapi
+const assetsFolder = "ui/assets"
+
+// assets 需要的文件，key 为压缩包中的路径，value 为 assetsFolder 下的文件名
+var assets = map[string]string{
+	"package/swagger-ui.css":       "swagger-ui.css",
+	"package/swagger-ui-bundle.js": "swagger-ui-bundle.js",
+	"package/LICENSE":              "LICENSE",
+}
+
+func main() {
+	if err := fetch(); err != nil {
+		fmt.Fprintln(os.Stderr, "fetch swagger-ui-dist:", err)
+		os.Exit(1)
+	}
+}
+
+func fetch() error {
+	url := fmt.Sprintf("https://registry.npmjs.org/swagger-ui-dist/-/swagger-ui-dist-%s.tgz", swaggerUIVersion)
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(assetsFolder, os.ModePerm); err != nil {
+		return err
+	}
+
+	found := 0
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		name, ok := assets[header.Name]
+		if !ok {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(assetsFolder, name), data, 0644); err != nil {
+			return err
+		}
+		found++
+	}
+	if found != len(assets) {
+		return fmt.Errorf("swagger-ui-dist %s: found %d of %d files", swaggerUIVersion, found, len(assets))
+	}
+	fmt.Println("swagger-ui-dist", swaggerUIVersion, "saved to", assetsFolder)
+	return nil
+}
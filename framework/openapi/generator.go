@@ -0,0 +1,329 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gothms/httpgo/framework/gin"
+)
+
+// defaultSecuritySchemes 未在 Options.SecuritySchemes 中定义时使用的安全方案
+var defaultSecuritySchemes = map[string]*SecurityScheme{
+	"bearer": {Type: "http", Scheme: "bearer"},
+	"basic":  {Type: "http", Scheme: "basic"},
+}
+
+// Options 生成文档的选项
+type Options struct {
+	Info    Info
+	Servers []Server
+	// Host 只生成该 host 模式下的路由，为空时生成默认路由树中的路由
+	Host string
+	// SecuritySchemes 路由中使用的安全方案，bearer 和 basic 有默认的定义
+	SecuritySchemes map[string]*SecurityScheme
+	// Skip 返回 true 的路由不生成文档
+	Skip func(route gin.RouteInfo) bool
+}
+
+// Generate 根据 Engine.Routes() 返回的路由生成 OpenAPI 文档
+// 路由的 Doc 中 Request 的字段按标签生成参数：uri 为路径参数，header 为请求头，
+// form 为查询参数（GET/HEAD/DELETE 等没有请求体的方法）或者表单请求体，其余字段按 json 标签生成 JSON 请求体
+func Generate(routes gin.RoutesInfo, opts Options) (*Document, error) {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    opts.Info,
+		Servers: opts.Servers,
+		Paths:   make(map[string]*PathItem),
+	}
+	registry := newSchemaRegistry()
+	schemes := make(map[string]*SecurityScheme)
+
+	for _, route := range routes {
+		if route.Host != opts.Host || (opts.Skip != nil && opts.Skip(route)) {
+			continue
+		}
+		path, params := convertPath(route.Path)
+		op, err := newOperation(route, params, registry)
+		if err != nil {
+			return nil, err
+		}
+		if route.Doc != nil {
+			for _, name := range route.Doc.Security {
+				scheme, ok := opts.SecuritySchemes[name]
+				if !ok {
+					scheme, ok = defaultSecuritySchemes[name]
+				}
+				if !ok {
+					return nil, fmt.Errorf("openapi: security scheme '%s' of route %s %s not defined", name, route.Method, route.Path)
+				}
+				schemes[name] = scheme
+			}
+		}
+
+		item := doc.Paths[path]
+		if item == nil {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		if slot := item.operation(route.Method); slot != nil {
+			*slot = op
+		}
+	}
+
+	if len(registry.schemas) > 0 || len(schemes) > 0 {
+		doc.Components = &Components{}
+		if len(registry.schemas) > 0 {
+			doc.Components.Schemas = registry.schemas
+		}
+		if len(schemes) > 0 {
+			doc.Components.SecuritySchemes = schemes
+		}
+	}
+	return doc, nil
+}
+
+// operation 返回请求方法在 PathItem 中对应的字段，不支持的方法返回 nil
+func (item *PathItem) operation(method string) **Operation {
+	switch method {
+	case http.MethodGet:
+		return &item.Get
+	case http.MethodPut:
+		return &item.Put
+	case http.MethodPost:
+		return &item.Post
+	case http.MethodDelete:
+		return &item.Delete
+	case http.MethodOptions:
+		return &item.Options
+	case http.MethodHead:
+		return &item.Head
+	case http.MethodPatch:
+		return &item.Patch
+	case http.MethodTrace:
+		return &item.Trace
+	}
+	return nil
+}
+
+// newOperation 根据路由和它的文档信息生成接口，pathParams 为从路径中解析出的参数
+func newOperation(route gin.RouteInfo, pathParams []*Parameter, registry *schemaRegistry) (*Operation, error) {
+	op := &Operation{
+		OperationID: route.Name,
+		Responses:   make(map[string]*Response),
+	}
+	rd := route.Doc
+	if rd == nil {
+		rd = &gin.RouteDoc{}
+	}
+	op.Summary = rd.Summary
+	op.Description = rd.Description
+	op.Tags = rd.Tags
+	op.Deprecated = rd.Deprecated
+	for _, name := range rd.Security {
+		op.Security = append(op.Security, map[string][]string{name: {}})
+	}
+
+	params := pathParams
+	if rd.Request != nil {
+		var err error
+		if params, err = requestParams(route.Method, rd.Request, op, pathParams, registry); err != nil {
+			return nil, fmt.Errorf("openapi: route %s %s: %w", route.Method, route.Path, err)
+		}
+	}
+	op.Parameters = params
+
+	for code, body := range rd.Responses {
+		response := &Response{Description: http.StatusText(code)}
+		if body != nil {
+			response.Content = map[string]*MediaType{
+				gin.MIMEJSON: {Schema: registry.schemaOf(reflect.TypeOf(body))},
+			}
+		}
+		op.Responses[strconv.Itoa(code)] = response
+	}
+	if len(op.Responses) == 0 {
+		op.Responses["200"] = &Response{Description: http.StatusText(http.StatusOK)}
+	}
+	return op, nil
+}
+
+// requestParams 按字段的标签把请求类型拆分为参数和请求体，返回合并了路径参数后的参数列表
+func requestParams(method string, request interface{}, op *Operation, pathParams []*Parameter, registry *schemaRegistry) ([]*Parameter, error) {
+	t := reflect.TypeOf(request)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("request type %s is not a struct", t)
+	}
+
+	hasBody := method != http.MethodGet && method != http.MethodHead && method != http.MethodDelete
+	params := append([]*Parameter(nil), pathParams...)
+	form := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	body := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	// split 为 true 表示有字段不在 JSON 请求体中
+	split := false
+	for _, field := range structFields(t) {
+		required := isRequired(field)
+		if name, tagged, ok := fieldName(field, "uri"); tagged {
+			split = true
+			if ok {
+				setParam(&params, &Parameter{Name: name, In: "path", Required: true, Schema: registry.schemaOf(field.Type)})
+			}
+			continue
+		}
+		if name, tagged, ok := fieldName(field, "header"); tagged {
+			split = true
+			if ok {
+				setParam(&params, &Parameter{Name: name, In: "header", Required: required, Schema: registry.schemaOf(field.Type)})
+			}
+			continue
+		}
+		_, jsonTagged := field.Tag.Lookup("json")
+		if name, tagged, ok := fieldName(field, "form"); (tagged && !jsonTagged) || !hasBody {
+			split = true
+			if !ok {
+				continue
+			}
+			if hasBody {
+				form.Properties[name] = registry.schemaOf(field.Type)
+				if required {
+					form.Required = append(form.Required, name)
+				}
+				continue
+			}
+			setParam(&params, &Parameter{Name: name, In: "query", Required: required, Schema: registry.schemaOf(field.Type)})
+			continue
+		}
+		if name, _, ok := fieldName(field, "json"); ok {
+			body.Properties[name] = registry.schemaOf(field.Type)
+			if required {
+				body.Required = append(body.Required, name)
+			}
+		}
+	}
+
+	content := make(map[string]*MediaType)
+	if len(body.Properties) > 0 {
+		// 所有字段都在 JSON 请求体中时，直接引用结构体的定义
+		if !split {
+			body = registry.schemaOf(t)
+		}
+		content[gin.MIMEJSON] = &MediaType{Schema: body}
+	}
+	if len(form.Properties) > 0 {
+		content[gin.MIMEPOSTForm] = &MediaType{Schema: form}
+	}
+	if len(content) > 0 {
+		op.RequestBody = &RequestBody{Required: true, Content: content}
+	}
+	return params, nil
+}
+
+// setParam 添加参数，同名同位置的参数会被替换，例如 uri 标签的字段替换从路径中解析出的参数
+func setParam(params *[]*Parameter, param *Parameter) {
+	for i, p := range *params {
+		if p.Name == param.Name && p.In == param.In {
+			(*params)[i] = param
+			return
+		}
+	}
+	*params = append(*params, param)
+}
+
+// convertPath 把 gin 的路由路径转换为 OpenAPI 的路径，并返回路径中的参数
+// 例如：/user/:id/{slug:[a-z]+}/*file => /user/{id}/{slug}/{file}
+func convertPath(path string) (string, []*Parameter) {
+	var params []*Parameter
+	var sb strings.Builder
+	for len(path) > 0 {
+		i := strings.IndexAny(path, ":*{")
+		if i < 0 || (i > 0 && path[i-1] != '/') {
+			if i < 0 {
+				sb.WriteString(path)
+				break
+			}
+			sb.WriteString(path[:i+1])
+			path = path[i+1:]
+			continue
+		}
+		sb.WriteString(path[:i])
+		path = path[i:]
+
+		var name string
+		schema := &Schema{Type: "string"}
+		if path[0] == '{' {
+			end := closingBrace(path)
+			if end < 0 {
+				sb.WriteString(path)
+				break
+			}
+			var expr string
+			name, expr, _ = strings.Cut(path[1:end], ":")
+			if expr != "" {
+				schema = constraintSchema(expr)
+			}
+			path = path[end+1:]
+		} else {
+			end := strings.IndexByte(path, '/')
+			if end < 0 {
+				end = len(path)
+			}
+			name = path[1:end]
+			path = path[end:]
+		}
+		sb.WriteString("{" + name + "}")
+		params = append(params, &Parameter{Name: name, In: "path", Required: true, Schema: schema})
+	}
+	return sb.String(), params
+}
+
+// closingBrace 返回和 path[0] 处的 '{' 配对的 '}' 的位置，找不到返回 -1
+func closingBrace(path string) int {
+	depth := 0
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// constraintSchema 根据路由参数的约束生成 schema，未知的约束按正则表达式处理
+func constraintSchema(expr string) *Schema {
+	switch expr {
+	case "int":
+		return &Schema{Type: "integer", Format: "int64"}
+	case "uint":
+		return &Schema{Type: "integer", Format: "int64"}
+	case "alpha":
+		return &Schema{Type: "string", Pattern: "^[a-zA-Z]+$"}
+	case "uuid":
+		return &Schema{Type: "string", Format: "uuid"}
+	}
+	// 只包含字母、数字和下划线的是通过 gin.RegisterParamConstraint 注册的约束，无法转换为 schema
+	if isIdentifier(expr) {
+		return &Schema{Type: "string"}
+	}
+	return &Schema{Type: "string", Pattern: "^(?:" + expr + ")$"}
+}
+
+func isIdentifier(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,160 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gothms/httpgo/framework/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testUser struct {
+	ID        int64       `json:"id"`
+	Name      string      `json:"name" binding:"required"`
+	Tags      []string    `json:"tags,omitempty"`
+	Friends   []*testUser `json:"friends"`
+	CreatedAt time.Time   `json:"created_at"`
+	Secret    string      `json:"-"`
+}
+
+type testQuery struct {
+	ID    int    `uri:"id"`
+	Page  int    `form:"page" binding:"required"`
+	Size  int    `form:"size"`
+	Token string `header:"X-Token"`
+}
+
+type testUpdate struct {
+	ID     int    `uri:"id"`
+	Notify bool   `form:"notify"`
+	Name   string `json:"name" binding:"required"`
+}
+
+func TestGenerate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := func(c *gin.Context) {}
+	router.GET("/user/:id/books", handler).Doc(gin.RouteDoc{
+		Summary:   "books",
+		Tags:      []string{"user"},
+		Request:   testQuery{},
+		Responses: map[int]interface{}{200: []testUser{}, 404: nil},
+		Security:  []string{"bearer"},
+	}).Name("user.books")
+	router.PUT("/user/{id:int}", handler).Doc(gin.RouteDoc{Request: testUpdate{}})
+	router.POST("/user", handler).Doc(gin.RouteDoc{Request: &testUser{}})
+	router.GET("/files/{name:[a-z]+}/*path", handler)
+	router.Host("admin.example.com").GET("/admin", handler)
+
+	doc, err := Generate(router.Routes(), Options{Info: Info{Title: "test", Version: "1.0"}})
+	require.NoError(t, err)
+	assert.Equal(t, Version, doc.OpenAPI)
+	assert.Len(t, doc.Paths, 4)
+	assert.NotContains(t, doc.Paths, "/admin")
+
+	op := doc.Paths["/user/{id}/books"].Get
+	require.NotNil(t, op)
+	assert.Equal(t, "user.books", op.OperationID)
+	assert.Equal(t, []map[string][]string{{"bearer": {}}}, op.Security)
+	assert.Equal(t, []*Parameter{
+		{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "integer", Format: "int32"}},
+		{Name: "page", In: "query", Required: true, Schema: &Schema{Type: "integer", Format: "int32"}},
+		{Name: "size", In: "query", Schema: &Schema{Type: "integer", Format: "int32"}},
+		{Name: "X-Token", In: "header", Schema: &Schema{Type: "string"}},
+	}, op.Parameters)
+	assert.Equal(t, &Schema{Type: "array", Items: &Schema{Ref: "#/components/schemas/testUser"}},
+		op.Responses["200"].Content[gin.MIMEJSON].Schema)
+	assert.Equal(t, "Not Found", op.Responses["404"].Description)
+	assert.Nil(t, op.Responses["404"].Content)
+
+	user := doc.Components.Schemas["testUser"]
+	require.NotNil(t, user)
+	assert.Equal(t, []string{"name"}, user.Required)
+	assert.NotContains(t, user.Properties, "Secret")
+	assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, user.Properties["created_at"])
+	assert.Equal(t, &Schema{Ref: "#/components/schemas/testUser"}, user.Properties["friends"].Items)
+	assert.Equal(t, "http", doc.Components.SecuritySchemes["bearer"].Type)
+
+	op = doc.Paths["/user/{id}"].Put
+	require.NotNil(t, op)
+	assert.Equal(t, "path", op.Parameters[0].In)
+	assert.Equal(t, &Schema{Type: "object", Properties: map[string]*Schema{"name": {Type: "string"}}, Required: []string{"name"}},
+		op.RequestBody.Content[gin.MIMEJSON].Schema)
+	assert.Equal(t, &Schema{Type: "object", Properties: map[string]*Schema{"notify": {Type: "boolean"}}},
+		op.RequestBody.Content[gin.MIMEPOSTForm].Schema)
+
+	op = doc.Paths["/user"].Post
+	require.NotNil(t, op)
+	assert.Equal(t, &Schema{Ref: "#/components/schemas/testUser"}, op.RequestBody.Content[gin.MIMEJSON].Schema)
+	assert.Equal(t, "OK", op.Responses["200"].Description)
+
+	op = doc.Paths["/files/{name}/{path}"].Get
+	require.NotNil(t, op)
+	assert.Equal(t, &Schema{Type: "string", Pattern: "^(?:[a-z]+)$"}, op.Parameters[0].Schema)
+	assert.Equal(t, "path", op.Parameters[1].Name)
+
+	doc, err = Generate(router.Routes(), Options{Host: "admin.example.com"})
+	require.NoError(t, err)
+	assert.Len(t, doc.Paths, 1)
+	assert.Contains(t, doc.Paths, "/admin")
+}
+
+func TestGenerateUnknownSecurityScheme(t *testing.T) {
+	router := gin.New()
+	router.GET("/", func(c *gin.Context) {}).Doc(gin.RouteDoc{Security: []string{"oauth"}})
+	_, err := Generate(router.Routes(), Options{})
+	assert.Error(t, err)
+
+	_, err = Generate(router.Routes(), Options{SecuritySchemes: map[string]*SecurityScheme{
+		"oauth": {Type: "apiKey", Name: "token", In: "query"},
+	}})
+	assert.NoError(t, err)
+}
+
+func TestConvertPath(t *testing.T) {
+	path, params := convertPath("/user/:id/{slug:[a-z]{2,4}}/{uuid:uuid}/*file")
+	assert.Equal(t, "/user/{id}/{slug}/{uuid}/{file}", path)
+	assert.Len(t, params, 4)
+	assert.Equal(t, "^(?:[a-z]{2,4})$", params[1].Schema.Pattern)
+	assert.Equal(t, "uuid", params[2].Schema.Format)
+
+	path, params = convertPath("/static")
+	assert.Equal(t, "/static", path)
+	assert.Nil(t, params)
+}
+
+func TestRegisterUI(t *testing.T) {
+	router := gin.New()
+	router.GET("/book", func(c *gin.Context) {})
+	RegisterUI(router, "/swagger", Options{Info: Info{Title: "test"}})
+
+	handler, _, _, _ := router.Lookup(http.MethodGet, "/swagger/openapi.json")
+	require.NotEmpty(t, handler)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/swagger/openapi.json", nil)
+	handler.Last()(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"/book"`)
+	assert.NotContains(t, w.Body.String(), `"/swagger`)
+}
+
+func TestUIIndexUsesLocalAssets(t *testing.T) {
+	index, err := uiFS.ReadFile("ui/index.html")
+	require.NoError(t, err)
+	assert.NotContains(t, string(index), "://")
+	assert.Contains(t, string(index), `href="assets/swagger-ui.css"`)
+	assert.Contains(t, string(index), `src="assets/swagger-ui-bundle.js"`)
+
+	router := gin.New()
+	RegisterUI(router, "/swagger", Options{})
+	for _, asset := range []string{"swagger-ui.css", "swagger-ui-bundle.js", "LICENSE"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/swagger/assets/"+asset, nil))
+		assert.Equal(t, http.StatusOK, w.Code, asset)
+		assert.NotZero(t, w.Body.Len(), asset)
+	}
+}
@@ -0,0 +1,160 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	emptyObjectSchema = &Schema{Type: "object"}
+)
+
+// schemaRegistry 记录已经生成的结构体 schema，结构体通过 $ref 引用 components 中的定义
+type schemaRegistry struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{
+		schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+// schemaOf 返回类型对应的 schema
+func (r *schemaRegistry) schemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: r.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schemaOf(t.Elem())}
+	case reflect.Struct:
+		return r.structRef(t)
+	default:
+		// interface{} 等无法确定的类型，不限制
+		return &Schema{}
+	}
+}
+
+// structRef 把结构体的 schema 放到 components 中，返回对它的引用，匿名结构体直接内联
+func (r *schemaRegistry) structRef(t reflect.Type) *Schema {
+	if t.Name() == "" {
+		return r.structSchema(t, "json")
+	}
+	if name, ok := r.names[t]; ok {
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+
+	name := t.Name()
+	// 不同包中的同名结构体，加上包名区分
+	if _, ok := r.schemas[name]; ok {
+		pkg := t.PkgPath()
+		name = pkg[strings.LastIndexByte(pkg, '/')+1:] + "." + name
+	}
+	r.names[t] = name
+	// 先占位，避免递归引用的结构体无限展开
+	r.schemas[name] = emptyObjectSchema
+	r.schemas[name] = r.structSchema(t, "json")
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// structSchema 按 tag 标签生成结构体的 schema，没有 tag 的字段使用字段名，tag 为 - 的字段忽略
+func (r *schemaRegistry) structSchema(t reflect.Type, tag string) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for _, field := range structFields(t) {
+		name, _, ok := fieldName(field, tag)
+		if !ok {
+			continue
+		}
+		schema.Properties[name] = r.schemaOf(field.Type)
+		if isRequired(field) {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
+
+// structFields 返回结构体中导出的字段，匿名嵌入的结构体字段会展开
+func structFields(t reflect.Type) []reflect.StructField {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			ft := field.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+				fields = append(fields, structFields(ft)...)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// fieldName 返回字段在 tag 中的名字，tagged 表示字段是否显式声明了该 tag，ok 为 false 时忽略该字段
+func fieldName(field reflect.StructField, tag string) (name string, tagged bool, ok bool) {
+	value, tagged := field.Tag.Lookup(tag)
+	if value == "-" {
+		return "", tagged, false
+	}
+	name, _, _ = strings.Cut(value, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, tagged, true
+}
+
+// isRequired 字段的 binding 标签中是否声明了 required
+func isRequired(field reflect.StructField) bool {
+	for _, rule := range strings.Split(field.Tag.Get("binding"), ",") {
+		if rule == "required" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package openapi
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gothms/httpgo/framework/gin"
+)
+
+//go:generate go run fetch_ui.go
+
+// uiFS Swagger UI 的页面，页面中的脚本和样式使用 ui/assets 下的 swagger-ui-dist，不依赖外部 CDN
+//
+//go:embed ui
+var uiFS embed.FS
+
+// RegisterUI 在 prefix 下注册 Swagger UI：prefix/ 为页面，prefix/assets/ 为页面的脚本和样式，prefix/openapi.json 为根据 engine 的路由生成的文档
+// 文档在第一次请求时生成，此时所有路由都已注册完成，prefix 下的路由不会出现在文档中
+func RegisterUI(engine *gin.Engine, prefix string, opts Options) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	index, err := uiFS.ReadFile("ui/index.html")
+	if err != nil {
+		panic(err)
+	}
+	assets, err := fs.Sub(uiFS, "ui/assets")
+	if err != nil {
+		panic(err)
+	}
+
+	skip := opts.Skip
+	opts.Skip = func(route gin.RouteInfo) bool {
+		if route.Path == prefix || strings.HasPrefix(route.Path, prefix+"/") {
+			return true
+		}
+		return skip != nil && skip(route)
+	}
+	var once sync.Once
+	var doc *Document
+	var docErr error
+
+	engine.GET(prefix, func(c *gin.Context) {
+		c.Redirect(http.StatusMovedPermanently, prefix+"/")
+	})
+	engine.GET(prefix+"/", func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", index)
+	})
+	engine.StaticFS(prefix+"/assets", http.FS(assets))
+	engine.GET(prefix+"/openapi.json", func(c *gin.Context) {
+		once.Do(func() {
+			doc, docErr = Generate(engine.Routes(), opts)
+		})
+		if docErr != nil {
+			c.String(http.StatusInternalServerError, docErr.Error())
+			return
+		}
+		c.JSON(http.StatusOK, doc)
+	})
+}
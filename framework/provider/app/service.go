@@ -1,25 +1,48 @@
 package app
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
-	"flag"
 	"github.com/gothms/httpgo/framework"
 	"github.com/gothms/httpgo/framework/contract"
 	"github.com/gothms/httpgo/framework/util"
+	"os"
 	"path/filepath"
+	"strings"
+)
+
+// 编译信息，由 build 命令通过 ldflags 注入，例如：
+// go build -ldflags "-X github.com/gothms/httpgo/framework/provider/app.Version=v1.0.0"
+var (
+	// Version 应用版本
+	Version = "0.0.1"
+	// GitCommit 编译时的 git commit
+	GitCommit = ""
+	// BuildTime 编译时间
+	BuildTime = ""
 )
 
 // HttpgoApp 代表 httpgo 框架的 App 实现
 type HttpgoApp struct {
 	container  framework.Container // 服务容器
 	baseFolder string              // 基础路径
+	appID      string              // 实例的唯一标识
 }
 
 var _ contract.App = (*HttpgoApp)(nil)
 
-// Version 实现版本
+// Version 实现版本，注入了 git commit 时以 semver 的 build metadata 形式附加在版本后
 func (h HttpgoApp) Version() string {
-	return "0.0.1"
+	if GitCommit != "" {
+		return Version + "+" + GitCommit
+	}
+	return Version
+}
+
+// AppID 表示当前实例的唯一标识
+func (h HttpgoApp) AppID() string {
+	return h.appID
 }
 
 // BaseFolder 表示基础目录，可以代表开发场景的目录，也可以代表运行时候的目录
@@ -28,10 +51,7 @@ func (h HttpgoApp) BaseFolder() string {
 		return h.baseFolder
 	}
 	// 如果没有设置，则使用参数
-	var baseFolder string
-	flag.StringVar(&baseFolder, "base_folder", "", "base_folder参数, 默认为当前路径")
-	flag.Parse()
-	if baseFolder != "" {
+	if baseFolder := baseFolderFromArgs(os.Args[1:]); baseFolder != "" {
 		return baseFolder
 	}
 
@@ -39,6 +59,27 @@ func (h HttpgoApp) BaseFolder() string {
 	return util.GetExecDirectory()
 }
 
+// baseFolderFromArgs 从命令行参数中解析 --base_folder
+// 命令行参数由 cobra 解析，这里不能使用 flag.Parse，否则会和 cobra 的参数冲突，并且重复定义 flag 会 panic
+func baseFolderFromArgs(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		name := strings.TrimLeft(arg, "-")
+		if name == arg {
+			continue
+		}
+		if strings.HasPrefix(name, "base_folder=") {
+			return strings.TrimPrefix(name, "base_folder=")
+		}
+		if name == "base_folder" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
 // ConfigFolder  表示配置文件地址
 func (h HttpgoApp) ConfigFolder() string {
 	return filepath.Join(h.BaseFolder(), "config")
@@ -57,6 +98,7 @@ func (h HttpgoApp) ConsoleFolder() string {
 	return filepath.Join(h.BaseFolder(), "console")
 }
 
+// StorageFolder 存放运行时产生的文件
 func (h HttpgoApp) StorageFolder() string {
 	return filepath.Join(h.BaseFolder(), "storage")
 }
@@ -94,5 +136,18 @@ func NewHttpgoApp(params ...interface{}) (interface{}, error) {
 	// 有两个参数，一个是容器，一个是 baseFolder
 	container := params[0].(framework.Container)
 	baseFolder := params[1].(string)
-	return &HttpgoApp{container: container, baseFolder: baseFolder}, nil
+	appID, err := newAppID()
+	if err != nil {
+		return nil, err
+	}
+	return &HttpgoApp{container: container, baseFolder: baseFolder, appID: appID}, nil
+}
+
+// newAppID 生成随机的实例标识
+func newAppID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
 }
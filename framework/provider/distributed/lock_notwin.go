@@ -0,0 +1,23 @@
+//go:build !windows
+// +build !windows
+
+package distributed
+
+import (
+	"os"
+	"syscall"
+)
+
+// tryLockFile 非阻塞地对文件加排他锁，锁被其他进程持有时返回 false
+func tryLockFile(f *os.File) (bool, error) {
+	err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// unlockFile 释放文件锁
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,128 @@
+package distributed
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gothms/httpgo/framework"
+	"github.com/gothms/httpgo/framework/contract"
+)
+
+// lockTimeout 等待租约文件锁的最长时间
+const lockTimeout = 10 * time.Second
+
+// LocalDistributedService 基于本地文件的分布式选择器，适用于同一台机器上的多个进程
+// 每个服务在 RuntimeFolder/distribute 下有一个租约文件，内容为持有者的 appID 和过期时间
+type LocalDistributedService struct {
+	container framework.Container
+	// folder 租约文件所在目录
+	folder string
+	once   sync.Once
+}
+
+var _ contract.Distributed = (*LocalDistributedService)(nil)
+
+// NewLocalDistributedService 初始化本地分布式选择器
+func NewLocalDistributedService(params ...interface{}) (interface{}, error) {
+	if len(params) != 1 {
+		return nil, errors.New("param error")
+	}
+	container := params[0].(framework.Container)
+	return &LocalDistributedService{container: container}, nil
+}
+
+// leaseFolder 租约文件所在目录
+// 在使用时才从容器中获取 App 服务，实例化时容器正在绑定服务，不能再从容器中获取服务
+func (s *LocalDistributedService) leaseFolder() string {
+	s.once.Do(func() {
+		if s.folder == "" {
+			appService := s.container.MustMake(contract.AppKey).(contract.App)
+			s.folder = filepath.Join(appService.RuntimeFolder(), "distribute")
+		}
+	})
+	return s.folder
+}
+
+// Select 抢占租约，租约不存在、已过期或者持有者是自己时，由当前实例持有并续期
+func (s *LocalDistributedService) Select(serviceName string, appID string, holdTime time.Duration) (string, error) {
+	folder := s.leaseFolder()
+	if err := os.MkdirAll(folder, os.ModePerm); err != nil {
+		return "", err
+	}
+	leaseFile := filepath.Join(folder, serviceName)
+	unlock, err := s.lock(leaseFile + ".lock")
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	now := time.Now()
+	owner, expireAt, err := readLease(leaseFile)
+	if err != nil {
+		return "", err
+	}
+	if owner != "" && owner != appID && now.Before(expireAt) {
+		return owner, nil
+	}
+
+	content := appID + " " + strconv.FormatInt(now.Add(holdTime).UnixNano(), 10)
+	if err := os.WriteFile(leaseFile, []byte(content), 0644); err != nil {
+		return "", err
+	}
+	return appID, nil
+}
+
+// lock 对锁文件加排他的文件锁实现进程间互斥，返回解锁函数
+// 锁文件一直保留，进程退出时操作系统会释放它持有的文件锁，不会留下需要清理的过期锁
+func (s *LocalDistributedService) lock(lockFile string) (func(), error) {
+	f, err := os.OpenFile(lockFile, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		locked, err := tryLockFile(f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		if locked {
+			return func() {
+				unlockFile(f)
+				f.Close()
+			}, nil
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("acquire lock %s timeout", lockFile)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// readLease 读取租约文件，文件不存在时返回空的持有者
+func readLease(leaseFile string) (string, time.Time, error) {
+	content, err := os.ReadFile(leaseFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", time.Time{}, nil
+		}
+		return "", time.Time{}, err
+	}
+	fields := strings.Fields(string(content))
+	if len(fields) != 2 {
+		// 内容损坏，视为没有持有者
+		return "", time.Time{}, nil
+	}
+	expireAt, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return "", time.Time{}, nil
+	}
+	return fields[0], time.Unix(0, expireAt), nil
+}
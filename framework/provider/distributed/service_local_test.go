@@ -0,0 +1,89 @@
+package distributed
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLocalDistributedSelect(t *testing.T) {
+	s := &LocalDistributedService{folder: t.TempDir()}
+
+	selected, err := s.Select("job", "app1", 200*time.Millisecond)
+	if err != nil || selected != "app1" {
+		t.Fatalf("app1 should hold the lease, got %s %v", selected, err)
+	}
+	if selected, _ = s.Select("job", "app2", 200*time.Millisecond); selected != "app1" {
+		t.Fatalf("app2 should not take an unexpired lease, got %s", selected)
+	}
+	// 其他服务的租约互不影响
+	if selected, _ = s.Select("other", "app2", 200*time.Millisecond); selected != "app2" {
+		t.Fatalf("app2 should hold the other lease, got %s", selected)
+	}
+	// 持有者续期
+	if selected, _ = s.Select("job", "app1", 200*time.Millisecond); selected != "app1" {
+		t.Fatalf("app1 should renew its lease, got %s", selected)
+	}
+
+	time.Sleep(250 * time.Millisecond)
+	if selected, _ = s.Select("job", "app2", 200*time.Millisecond); selected != "app2" {
+		t.Fatalf("app2 should take the expired lease, got %s", selected)
+	}
+}
+
+func TestLocalDistributedSelectConcurrent(t *testing.T) {
+	folder := t.TempDir()
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	winners := map[string]bool{}
+	for _, appID := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		wg.Add(1)
+		go func(appID string) {
+			defer wg.Done()
+			s := &LocalDistributedService{folder: folder}
+			selected, err := s.Select("job", appID, time.Minute)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			lock.Lock()
+			winners[selected] = true
+			lock.Unlock()
+		}(appID)
+	}
+	wg.Wait()
+	if len(winners) != 1 {
+		t.Fatalf("expected exactly one winner, got %v", winners)
+	}
+}
+
+func TestLocalDistributedLock(t *testing.T) {
+	s := &LocalDistributedService{folder: t.TempDir()}
+	lockFile := filepath.Join(s.folder, "job.lock")
+
+	unlock, err := s.lock(lockFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 另一个打开的文件拿不到已被持有的锁
+	f, err := os.OpenFile(lockFile, os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if locked, err := tryLockFile(f); err != nil || locked {
+		t.Fatalf("lock should be held, got %v %v", locked, err)
+	}
+
+	unlock()
+	// 解锁后锁文件保留，其他持有者可以加锁
+	if _, err := os.Stat(lockFile); err != nil {
+		t.Fatalf("lock file should persist: %v", err)
+	}
+	if locked, err := tryLockFile(f); err != nil || !locked {
+		t.Fatalf("lock should be free, got %v %v", locked, err)
+	}
+	unlockFile(f)
+}
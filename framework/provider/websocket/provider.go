@@ -0,0 +1,37 @@
+package websocket
+
+import (
+	"github.com/gothms/httpgo/framework"
+	"github.com/gothms/httpgo/framework/contract"
+)
+
+// HttpgoWebSocketProvider 提供按房间管理 WebSocket 连接的服务，连接由 gin.Context.UpgradeWebSocket 建立
+type HttpgoWebSocketProvider struct {
+}
+
+var _ framework.ServiceProvider = (*HttpgoWebSocketProvider)(nil)
+
+// Register 注册方法
+func (h *HttpgoWebSocketProvider) Register(container framework.Container) framework.NewInstance {
+	return NewHttpgoWebSocketService
+}
+
+// Boot 启动调用
+func (h *HttpgoWebSocketProvider) Boot(container framework.Container) error {
+	return nil
+}
+
+// IsDefer 是否延迟初始化
+func (h *HttpgoWebSocketProvider) IsDefer() bool {
+	return true
+}
+
+// Params 获取初始化参数
+func (h *HttpgoWebSocketProvider) Params(container framework.Container) []interface{} {
+	return []interface{}{container}
+}
+
+// Name 获取字符串凭证
+func (h *HttpgoWebSocketProvider) Name() string {
+	return contract.WebSocketKey
+}
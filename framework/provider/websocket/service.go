@@ -0,0 +1,151 @@
+package websocket
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/gothms/httpgo/framework/contract"
+	"github.com/gothms/httpgo/framework/gin"
+)
+
+// HttpgoWebSocket contract.WebSocket 的默认实现，连接和房间保存在内存中
+type HttpgoWebSocket struct {
+	mu    sync.RWMutex
+	rooms map[string]map[contract.WebSocketConn]struct{}
+	conns map[contract.WebSocketConn]map[string]struct{}
+}
+
+var (
+	_ contract.WebSocket     = (*HttpgoWebSocket)(nil)
+	_ contract.WebSocketConn = (*gin.WebSocketConn)(nil)
+)
+
+// NewHttpgoWebSocketService 创建 HttpgoWebSocket
+func NewHttpgoWebSocketService(params ...interface{}) (interface{}, error) {
+	return &HttpgoWebSocket{
+		rooms: make(map[string]map[contract.WebSocketConn]struct{}),
+		conns: make(map[contract.WebSocketConn]map[string]struct{}),
+	}, nil
+}
+
+// Join 将连接加入房间
+func (h *HttpgoWebSocket) Join(conn contract.WebSocketConn, rooms ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	joined := h.conns[conn]
+	if joined == nil {
+		joined = make(map[string]struct{})
+		h.conns[conn] = joined
+	}
+	for _, room := range rooms {
+		members := h.rooms[room]
+		if members == nil {
+			members = make(map[contract.WebSocketConn]struct{})
+			h.rooms[room] = members
+		}
+		members[conn] = struct{}{}
+		joined[room] = struct{}{}
+	}
+}
+
+// Leave 将连接移出房间，房间为空时删除房间
+func (h *HttpgoWebSocket) Leave(conn contract.WebSocketConn, rooms ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.leave(conn, rooms...)
+}
+
+// Remove 将连接移出所有房间
+func (h *HttpgoWebSocket) Remove(conn contract.WebSocketConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for room := range h.conns[conn] {
+		h.leave(conn, room)
+	}
+	delete(h.conns, conn)
+}
+
+func (h *HttpgoWebSocket) leave(conn contract.WebSocketConn, rooms ...string) {
+	for _, room := range rooms {
+		if members := h.rooms[room]; members != nil {
+			delete(members, conn)
+			if len(members) == 0 {
+				delete(h.rooms, room)
+			}
+		}
+		if joined := h.conns[conn]; joined != nil {
+			delete(joined, room)
+			if len(joined) == 0 {
+				delete(h.conns, conn)
+			}
+		}
+	}
+}
+
+// Rooms 连接加入的房间，按名称排序
+func (h *HttpgoWebSocket) Rooms(conn contract.WebSocketConn) []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	rooms := make([]string, 0, len(h.conns[conn]))
+	for room := range h.conns[conn] {
+		rooms = append(rooms, room)
+	}
+	sort.Strings(rooms)
+	return rooms
+}
+
+// Members 房间中的连接数
+func (h *HttpgoWebSocket) Members(room string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.rooms[room])
+}
+
+// Broadcast 并发地向房间中除 except 以外的连接写入消息，一个慢连接不会阻塞其他连接
+func (h *HttpgoWebSocket) Broadcast(room string, messageType int, data []byte, except ...contract.WebSocketConn) int {
+	h.mu.RLock()
+	targets := make([]contract.WebSocketConn, 0, len(h.rooms[room]))
+	for conn := range h.rooms[room] {
+		if !containsConn(except, conn) {
+			targets = append(targets, conn)
+		}
+	}
+	h.mu.RUnlock()
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		sent   int
+		failed []contract.WebSocketConn
+	)
+	for _, conn := range targets {
+		wg.Add(1)
+		go func(conn contract.WebSocketConn) {
+			defer wg.Done()
+			err := conn.WriteMessage(messageType, data)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed = append(failed, conn)
+				return
+			}
+			sent++
+		}(conn)
+	}
+	wg.Wait()
+
+	for _, conn := range failed {
+		h.Remove(conn)
+		_ = conn.Close()
+	}
+	return sent
+}
+
+func containsConn(list []contract.WebSocketConn, conn contract.WebSocketConn) bool {
+	for _, c := range list {
+		if c == conn {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package websocket
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/gothms/httpgo/framework"
+	"github.com/gothms/httpgo/framework/contract"
+)
+
+type fakeConn struct {
+	mu       sync.Mutex
+	messages []string
+	fail     bool
+	closed   bool
+}
+
+func (c *fakeConn) WriteMessage(messageType int, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.fail {
+		return errors.New("broken pipe")
+	}
+	c.messages = append(c.messages, string(data))
+	return nil
+}
+
+func (c *fakeConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestHttpgoWebSocket(t *testing.T) {
+	container := framework.NewHttpgoContainer()
+	if err := container.Bind(&HttpgoWebSocketProvider{}); err != nil {
+		t.Fatal(err)
+	}
+	hub := container.MustMake(contract.WebSocketKey).(contract.WebSocket)
+
+	a, b, broken := &fakeConn{}, &fakeConn{}, &fakeConn{fail: true}
+	hub.Join(a, "lobby", "game")
+	hub.Join(b, "lobby")
+	hub.Join(broken, "lobby")
+	if rooms := hub.Rooms(a); !reflect.DeepEqual(rooms, []string{"game", "lobby"}) {
+		t.Fatalf("unexpected rooms %v", rooms)
+	}
+
+	if sent := hub.Broadcast("lobby", 1, []byte("hi"), a); sent != 1 {
+		t.Fatalf("expected 1 delivery, got %d", sent)
+	}
+	if len(a.messages) != 0 || !reflect.DeepEqual(b.messages, []string{"hi"}) {
+		t.Fatalf("unexpected messages %v %v", a.messages, b.messages)
+	}
+	// 写入失败的连接被移除并关闭
+	if !broken.closed || hub.Members("lobby") != 2 || len(hub.Rooms(broken)) != 0 {
+		t.Fatalf("broken connection should be removed")
+	}
+
+	hub.Leave(a, "game")
+	if hub.Members("game") != 0 {
+		t.Fatalf("game should be empty")
+	}
+	hub.Remove(a)
+	hub.Remove(b)
+	if hub.Members("lobby") != 0 || len(hub.Rooms(a)) != 0 {
+		t.Fatalf("lobby should be empty")
+	}
+}
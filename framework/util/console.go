@@ -26,8 +26,6 @@ func PrettyPrint(arr [][]string) {
 			}
 		}
 	}
-	fmt.Println(lens)
-	fmt.Println(colMaxs)
 	for i := 0; i < rows; i++ {
 		for j := 0; j < cols; j++ {
 			fmt.Print(arr[i][j])
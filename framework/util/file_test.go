@@ -0,0 +1,87 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.sh")
+	if err := os.WriteFile(src, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// 目标目录不存在时自动创建，并保留文件权限
+	dst := filepath.Join(dir, "a", "b", "dst.sh")
+	if err := CopyFile(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "#!/bin/sh\n" {
+		t.Fatalf("content = %q", data)
+	}
+	info, err := os.Stat(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&0100 == 0 {
+		t.Fatalf("mode should be kept, got %v", info.Mode())
+	}
+
+	// 覆盖已有的文件
+	if err := os.WriteFile(src, []byte("new"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := CopyFile(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "new" {
+		t.Fatalf("dst should be truncated, got %q", data)
+	}
+
+	if err := CopyFile(filepath.Join(dir, "missing"), dst); err == nil {
+		t.Fatal("copy missing file should fail")
+	}
+}
+
+func TestCopyFolder(t *testing.T) {
+	src := t.TempDir()
+	files := map[string]string{
+		"index.html":      "<html></html>",
+		"assets/app.js":   "console.log(1)",
+		"assets/img/logo": "png",
+	}
+	for name, content := range files {
+		path := filepath.Join(src, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(src, "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "dist")
+	if err := CopyFolder(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(dst, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != content {
+			t.Fatalf("%s = %q, want %q", name, data, content)
+		}
+	}
+	if !Exists(filepath.Join(dst, "empty")) {
+		t.Fatal("empty folder should be copied")
+	}
+}
@@ -5,7 +5,9 @@ import (
 	httpgo "github.com/gothms/httpgo/app/http"
 	"github.com/gothms/httpgo/framework"
 	"github.com/gothms/httpgo/framework/provider/app"
+	"github.com/gothms/httpgo/framework/provider/distributed"
 	"github.com/gothms/httpgo/framework/provider/kernel"
+	"github.com/gothms/httpgo/framework/provider/websocket"
 )
 
 func main() {
@@ -13,6 +15,10 @@ func main() {
 	container := framework.NewHttpgoContainer()
 	// 绑定App服务提供者
 	container.Bind(&app.HttpgoAppProvider{})
+	// 绑定分布式选择器，默认使用本地文件实现，多机部署时替换为其他实现
+	container.Bind(&distributed.LocalDistributedProvider{})
+	// 绑定 WebSocket 连接管理服务，延迟到第一次使用时实例化
+	container.Bind(&websocket.HttpgoWebSocketProvider{})
 	// 后续初始化需要绑定的服务提供者...
 
 	// 将HTTP引擎初始化,并且作为服务提供者绑定到服务容器中